@@ -6,6 +6,7 @@ import (
 
 	"github.com/SimonGino/i18n-manager/internal/config"
 	"github.com/SimonGino/i18n-manager/internal/manager"
+	"github.com/SimonGino/i18n-manager/internal/tm"
 	"github.com/urfave/cli/v2"
 )
 
@@ -20,6 +21,27 @@ func main() {
 				Aliases: []string{"k"},
 				Usage:   "Custom key for translation",
 			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Add translations without asking for confirmation",
+			},
+			&cli.StringFlag{
+				Name:  "key-style",
+				Usage: "How keys are generated: dotted, snake, camel, ai or hash (default from key_style)",
+			},
+			&cli.StringFlag{
+				Name:  "key-prefix",
+				Usage: "Prefix of generated keys (default from key_prefix, otherwise msg.)",
+			},
+			&cli.StringFlag{
+				Name:  "on-conflict",
+				Usage: "What to do if the key already exists: reuse, suffix, overwrite or abort (asks by default)",
+			},
+			&cli.BoolFlag{
+				Name:  "allow-duplicate",
+				Usage: "Do not look for existing keys with the same source text",
+			},
 		},
 		Commands: []*cli.Command{
 			{
@@ -32,6 +54,27 @@ func main() {
 						Aliases: []string{"k"},
 						Usage:   "Custom key for translation",
 					},
+					&cli.BoolFlag{
+						Name:    "yes",
+						Aliases: []string{"y"},
+						Usage:   "Add translations without asking for confirmation",
+					},
+					&cli.StringFlag{
+						Name:  "key-style",
+						Usage: "How keys are generated: dotted, snake, camel, ai or hash (default from key_style)",
+					},
+					&cli.StringFlag{
+						Name:  "key-prefix",
+						Usage: "Prefix of generated keys (default from key_prefix, otherwise msg.)",
+					},
+					&cli.StringFlag{
+						Name:  "on-conflict",
+						Usage: "What to do if the key already exists: reuse, suffix, overwrite or abort (asks by default)",
+					},
+					&cli.BoolFlag{
+						Name:  "allow-duplicate",
+						Usage: "Do not look for existing keys with the same source text",
+					},
 				},
 				Action: manager.HandleTranslate,
 			},
@@ -74,11 +117,360 @@ func main() {
 				},
 				Action: manager.HandleList,
 			},
+			{
+				Name:    "remove",
+				Aliases: []string{"rm"},
+				Usage:   "Remove keys from every language file",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:    "key",
+						Aliases: []string{"k"},
+						Usage:   "Key to remove (can be repeated)",
+					},
+					&cli.StringFlag{
+						Name:  "glob",
+						Usage: "Remove keys matching a glob pattern (e.g., 'msg.legacy.*')",
+					},
+					&cli.StringFlag{
+						Name:  "regex",
+						Usage: "Remove keys matching a regular expression",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Show what would be removed without changing any file",
+					},
+				},
+				Action: manager.HandleRemove,
+			},
+			{
+				Name:    "sync",
+				Aliases: []string{"s"},
+				Usage:   "Translate every missing value from the source language",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "lang",
+						Usage: "Only fill these target languages (can be repeated)",
+					},
+					&cli.StringFlag{
+						Name:  "key-prefix",
+						Usage: "Only fill keys starting with this prefix",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "List missing translations without calling the AI service",
+					},
+				},
+				Action: manager.HandleSync,
+			},
+			{
+				Name:      "rename",
+				Aliases:   []string{"mv"},
+				Usage:     "Rename a key in every language file and optionally in source code",
+				ArgsUsage: "OLD_KEY NEW_KEY",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "src",
+						Usage: "Also rewrite references in .java, .html, .jsp and .ftl files under this directory",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Show the diff without changing any file",
+					},
+					&cli.BoolFlag{
+						Name:    "yes",
+						Aliases: []string{"y"},
+						Usage:   "Apply changes without asking for confirmation",
+					},
+				},
+				Action: manager.HandleRename,
+			},
+			{
+				Name:  "tm",
+				Usage: "Manage the local translation memory",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "list",
+						Usage: "List translation memory entries",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "source-lang",
+								Usage: "Only entries with this source language",
+							},
+							&cli.StringFlag{
+								Name:  "target-lang",
+								Usage: "Only entries with this target language",
+							},
+							&cli.StringFlag{
+								Name:  "model",
+								Usage: "Only entries from this model (e.g., openai/gpt-4o, or 'human')",
+							},
+						},
+						Action: manager.HandleTMList,
+					},
+					{
+						Name:   "stats",
+						Usage:  "Show translation memory size and hit statistics",
+						Action: manager.HandleTMStats,
+					},
+					{
+						Name:      "search",
+						Usage:     "Find entries similar to a text",
+						ArgsUsage: "TEXT",
+						Flags: []cli.Flag{
+							&cli.Float64Flag{
+								Name:  "threshold",
+								Value: 0.6,
+								Usage: "Minimum similarity between 0 and 1",
+							},
+							&cli.IntFlag{
+								Name:  "limit",
+								Value: 10,
+								Usage: "Maximum number of results",
+							},
+							&cli.StringFlag{
+								Name:  "source-lang",
+								Usage: "Only entries with this source language",
+							},
+							&cli.StringFlag{
+								Name:  "target-lang",
+								Usage: "Only entries with this target language",
+							},
+						},
+						Action: manager.HandleTMSearch,
+					},
+					{
+						Name:  "import",
+						Usage: "Seed entries from the properties files, or import a JSON file created by 'tm export'",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "file",
+								Usage: "JSON file to import instead of the properties files",
+							},
+						},
+						Action: manager.HandleTMImport,
+					},
+					{
+						Name:  "export",
+						Usage: "Export entries as JSON",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "file",
+								Usage: "Write to this file instead of stdout",
+							},
+							&cli.StringFlag{
+								Name:  "source-lang",
+								Usage: "Only entries with this source language",
+							},
+							&cli.StringFlag{
+								Name:  "target-lang",
+								Usage: "Only entries with this target language",
+							},
+							&cli.StringFlag{
+								Name:  "model",
+								Usage: "Only entries from this model (e.g., openai/gpt-4o, or 'human')",
+							},
+						},
+						Action: manager.HandleTMExport,
+					},
+					{
+						Name:  "purge",
+						Usage: "Remove entries from the translation memory",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "all",
+								Usage: "Remove every entry",
+							},
+							&cli.DurationFlag{
+								Name:  "older-than",
+								Usage: "Only entries not updated within this duration (e.g., 720h)",
+							},
+							&cli.StringFlag{
+								Name:  "source-lang",
+								Usage: "Only entries with this source language",
+							},
+							&cli.StringFlag{
+								Name:  "target-lang",
+								Usage: "Only entries with this target language",
+							},
+							&cli.StringFlag{
+								Name:  "model",
+								Usage: "Only entries from this model (e.g., openai/gpt-4o, or 'human')",
+							},
+						},
+						Action: manager.HandleTMPurge,
+					},
+				},
+			},
+			{
+				Name:  "glossary",
+				Usage: "Manage the project glossary of required term translations",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "add",
+						Usage: "Add or update a term",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "source",
+								Usage: "Term in the source language (e.g., 工作空间)",
+							},
+							&cli.StringFlag{
+								Name:  "target",
+								Usage: "Required translation; omit to keep the term unchanged (e.g., brand names)",
+							},
+							&cli.StringFlag{
+								Name:  "source-lang",
+								Usage: "Source language (defaults to the configured source language)",
+							},
+							&cli.StringFlag{
+								Name:  "target-lang",
+								Usage: "Target language; omit to apply to every language",
+							},
+						},
+						Action: manager.HandleGlossaryAdd,
+					},
+					{
+						Name:  "list",
+						Usage: "List glossary terms",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "target-lang",
+								Usage: "Only terms that apply to this target language",
+							},
+						},
+						Action: manager.HandleGlossaryList,
+					},
+					{
+						Name:    "remove",
+						Aliases: []string{"rm"},
+						Usage:   "Remove a term",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "source",
+								Usage: "Term to remove",
+							},
+							&cli.StringFlag{
+								Name:  "target-lang",
+								Usage: "Only remove the term for this target language",
+							},
+						},
+						Action: manager.HandleGlossaryRemove,
+					},
+				},
+			},
 			{
 				Name:    "check",
 				Aliases: []string{"c"},
-				Usage:   "Check for missing translations",
-				Action:  manager.HandleCheck,
+				Usage:   "Check for missing translations, placeholder mismatches and duplicate keys",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "format",
+						Value: "text",
+						Usage: "Output format: text, json, junit, sarif or github",
+					},
+					&cli.StringSliceFlag{
+						Name:  "fail-on",
+						Usage: "Issue kinds that fail the check: missing, placeholder, duplicate (default: all)",
+					},
+				},
+				Action: manager.HandleCheck,
+			},
+			{
+				Name:  "lint",
+				Usage: "Check keys and values against the configured lint rules",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "fix",
+						Usage: "Fix the problems that can be fixed automatically",
+					},
+					&cli.BoolFlag{
+						Name:  "list-rules",
+						Usage: "List the rules with their configured severity",
+					},
+				},
+				Action: manager.HandleLint,
+			},
+			{
+				Name:  "scan",
+				Usage: "Find keys that are never used in source code and keys that are used but not defined",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "src",
+						Value: ".",
+						Usage: "Directory with .java, .html, .jsp and .ftl files",
+					},
+					&cli.BoolFlag{
+						Name:  "unused-only",
+						Usage: "Only report unused keys",
+					},
+					&cli.BoolFlag{
+						Name:  "undefined-only",
+						Usage: "Only report undefined keys",
+					},
+				},
+				Action: manager.HandleScan,
+			},
+			{
+				Name:  "dupes",
+				Usage: "List keys that share the same source text",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "lang",
+						Usage: "Language to compare (defaults to the source language)",
+					},
+					&cli.BoolFlag{
+						Name:  "near",
+						Usage: "Also group near-identical values (see duplicate_threshold)",
+					},
+				},
+				Action: manager.HandleDupes,
+			},
+			{
+				Name:  "extract",
+				Usage: "Move hard-coded Chinese strings in Java code into the properties files",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "src",
+						Value: ".",
+						Usage: "Directory with .java files",
+					},
+					&cli.StringFlag{
+						Name:  "key-style",
+						Usage: "How keys are generated: dotted, snake, camel, ai or hash (default from key_style)",
+					},
+					&cli.StringFlag{
+						Name:  "key-prefix",
+						Usage: "Prefix of generated keys (default from key_prefix, otherwise msg.)",
+					},
+					&cli.BoolFlag{
+						Name:  "rewrite",
+						Usage: "Replace the strings in the source code with message lookups (see extract_expression)",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Only list the strings that would be extracted",
+					},
+					&cli.BoolFlag{
+						Name:    "yes",
+						Aliases: []string{"y"},
+						Usage:   "Apply the changes without asking for confirmation",
+					},
+				},
+				Action: manager.HandleExtract,
+			},
+			{
+				Name:  "restore",
+				Usage: "Restore properties files from a backup",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "id",
+						Usage: "Backup to restore (defaults to the latest)",
+					},
+					&cli.BoolFlag{
+						Name:  "list",
+						Usage: "List available backups",
+					},
+				},
+				Action: manager.HandleRestore,
 			},
 			{
 				Name:  "config",
@@ -92,6 +484,10 @@ func main() {
 						Name:  "set-api-url",
 						Usage: "Set API URL (e.g., https://api.openai.com/v1/chat/completions)",
 					},
+					&cli.StringFlag{
+						Name:  "set-provider",
+						Usage: "Set translation provider (openai, azure, anthropic, deepl, ollama, llamacpp, mock)",
+					},
 					&cli.StringFlag{
 						Name:  "set-model",
 						Usage: "Set model name (e.g., gpt-3.5-turbo, gpt-4, deepseek-chat, qwen-plus)",
@@ -108,6 +504,10 @@ func main() {
 				Action: config.HandleConfig,
 			},
 		},
+		// 保存本次运行中更新的翻译记忆库
+		After: func(c *cli.Context) error {
+			return tm.Flush()
+		},
 	}
 
 	if err := app.Run(os.Args); err != nil {
@@ -0,0 +1,87 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+)
+
+const (
+	anthropicDefaultURL = "https://api.anthropic.com/v1"
+	anthropicVersion    = "2023-06-01"
+	anthropicMaxTokens  = 4096
+)
+
+func init() {
+	Register("anthropic", newAnthropic)
+}
+
+// anthropicModel talks to the Anthropic Messages API.
+type anthropicModel struct {
+	url    string
+	apiKey string
+	model  string
+}
+
+func newAnthropic(cfg *config.Config) (Translator, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("API密钥未设置。请运行:\ni18n-manager config --set-api-key YOUR_API_KEY")
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("AI模型未设置。请运行:\ni18n-manager config --set-model MODEL_NAME")
+	}
+	return &chatTranslator{model: &anthropicModel{
+		url:    endpoint(cfg, anthropicDefaultURL, "/messages"),
+		apiKey: cfg.APIKey,
+		model:  cfg.Model,
+	}}, nil
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	MaxTokens   int                `json:"max_tokens"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	Temperature float64            `json:"temperature"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+func (m *anthropicModel) chat(ctx context.Context, system, prompt string) (string, error) {
+	request := anthropicRequest{
+		Model:       m.model,
+		MaxTokens:   anthropicMaxTokens,
+		System:      system,
+		Messages:    []anthropicMessage{{Role: "user", Content: prompt}},
+		Temperature: 0.3,
+	}
+	headers := map[string]string{
+		"x-api-key":         m.apiKey,
+		"anthropic-version": anthropicVersion,
+	}
+
+	var resp anthropicResponse
+	if err := postJSON(ctx, m.url, headers, request, &resp); err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return text.String(), nil
+}
@@ -0,0 +1,242 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/SimonGino/i18n-manager/internal/placeholder"
+)
+
+const (
+	defaultBatchMaxTokens = 2000
+	batchMaxItems         = 50
+	batchRetries          = 2
+	batchSystemPrompt     = "你是一位专业翻译。输入是一个JSON对象，键是标识符，值是待翻译的文本。" +
+		"请翻译每个值，并返回具有完全相同键的JSON对象。只返回JSON，不要包含任何解释或额外内容。" + placeholderPrompt
+)
+
+// BatchItem is one keyed string of a batch translation.
+type BatchItem struct {
+	Key  string
+	Text string
+}
+
+type BatchRequest struct {
+	Items      []BatchItem
+	SourceLang string
+	TargetLang string
+	References []Reference // 翻译记忆库中相似文本的译文，仅作参考
+}
+
+// BatchError reports the items of a batch that could not be translated.
+type BatchError struct {
+	Failed map[string]error
+}
+
+func (e *BatchError) Error() string {
+	keys := make([]string, 0, len(e.Failed))
+	for key := range e.Failed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d个条目翻译失败", len(keys))
+	for _, key := range keys {
+		fmt.Fprintf(&b, "\n  %s: %v", key, e.Failed[key])
+	}
+	return b.String()
+}
+
+// TranslateBatch translates many strings with as few requests as possible.
+// Items are split into batches that fit the configured token budget, sent as
+// a JSON object and validated on return; items that failed, were missing
+// from a response or came back with broken placeholders are batched again
+// and resent, up to batchRetries times. Items known to the translation
+// memory are not sent. Translations that succeeded are always
+// returned, and a *BatchError lists the items that did not.
+func TranslateBatch(req BatchRequest) (map[string]string, error) {
+	t, err := getTranslator()
+	if err != nil {
+		return nil, err
+	}
+	bt, ok := t.(BatchTranslator)
+	if !ok {
+		return translateEach(req)
+	}
+
+	cfg := config.GetConfig()
+	results := make(map[string]string, len(req.Items))
+	failed := make(map[string]error)
+
+	// 先从翻译记忆库中查找，只把未命中的条目发送给翻译服务
+	var pending []BatchItem
+	sources := make(map[string]string, len(req.Items))
+	placeholders := make(map[string][]string, len(req.Items))
+	references := make(map[string][]Reference, len(req.Items))
+	for _, item := range req.Items {
+		itemReq := TranslationRequest{Text: item.Text, SourceLang: req.SourceLang, TargetLang: req.TargetLang}
+		if text, ok := lookupMemory(itemReq); ok {
+			results[item.Key] = text
+			continue
+		}
+		sources[item.Key] = item.Text
+		masked, p := placeholder.Mask(item.Text)
+		placeholders[item.Key] = p
+		references[item.Key] = memoryReferences(itemReq)
+		pending = append(pending, BatchItem{Key: item.Key, Text: masked})
+	}
+
+	for attempt := 0; attempt <= batchRetries && len(pending) > 0; attempt++ {
+		batches := splitBatches(pending, batchMaxTokens(cfg))
+		responses := make([]map[string]string, len(batches))
+		errs := make([]error, len(batches))
+		ForEach(len(batches), func(i int) {
+			batch := BatchRequest{Items: batches[i], SourceLang: req.SourceLang, TargetLang: req.TargetLang, References: batchReferences(batches[i], references)}
+			errs[i] = withRetry(2*batchTokens(batches[i])+estimateTokens(batchSystemPrompt), func(ctx context.Context) error {
+				var err error
+				responses[i], err = bt.TranslateBatch(ctx, batch)
+				return err
+			})
+		})
+
+		var retry []BatchItem
+		for i, batch := range batches {
+			translated, err := responses[i], errs[i]
+			for _, item := range batch {
+				if err != nil {
+					failed[item.Key] = err
+					retry = append(retry, item)
+					continue
+				}
+				text, ok := translated[item.Key]
+				if !ok || strings.TrimSpace(text) == "" {
+					failed[item.Key] = fmt.Errorf("响应中缺少该条目")
+					retry = append(retry, item)
+					continue
+				}
+				restored, err := restorePlaceholders(sources[item.Key], strings.TrimSpace(text), placeholders[item.Key])
+				if err != nil {
+					failed[item.Key] = err
+					retry = append(retry, item)
+					continue
+				}
+				results[item.Key] = restored
+				delete(failed, item.Key)
+				remember(TranslationRequest{Text: sources[item.Key], SourceLang: req.SourceLang, TargetLang: req.TargetLang}, restored)
+			}
+		}
+		pending = retry
+	}
+
+	if len(failed) > 0 {
+		return results, &BatchError{Failed: failed}
+	}
+	return results, nil
+}
+
+// batchReferences collects the references of the items in a batch, without
+// duplicates.
+func batchReferences(items []BatchItem, references map[string][]Reference) []Reference {
+	var refs []Reference
+	seen := make(map[Reference]bool)
+	for _, item := range items {
+		for _, r := range references[item.Key] {
+			if !seen[r] {
+				seen[r] = true
+				refs = append(refs, r)
+			}
+		}
+	}
+	return refs
+}
+
+// translateEach translates the items one by one for providers without batch
+// support.
+func translateEach(req BatchRequest) (map[string]string, error) {
+	reqs := make([]TranslationRequest, len(req.Items))
+	for i, item := range req.Items {
+		reqs[i] = TranslationRequest{Text: item.Text, SourceLang: req.SourceLang, TargetLang: req.TargetLang}
+	}
+
+	texts, errs := TranslateAll(reqs)
+	results := make(map[string]string, len(req.Items))
+	failed := make(map[string]error)
+	for i, item := range req.Items {
+		if errs[i] != nil {
+			failed[item.Key] = errs[i]
+			continue
+		}
+		results[item.Key] = texts[i]
+	}
+
+	if len(failed) > 0 {
+		return results, &BatchError{Failed: failed}
+	}
+	return results, nil
+}
+
+func batchMaxTokens(cfg *config.Config) int {
+	if cfg.BatchMaxTokens > 0 {
+		return cfg.BatchMaxTokens
+	}
+	return defaultBatchMaxTokens
+}
+
+// estimateTokens gives a rough token count: about four ASCII characters or
+// one CJK character per token.
+func estimateTokens(s string) int {
+	ascii, other := 0, 0
+	for _, r := range s {
+		if r < 128 {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return ascii/4 + other + 1
+}
+
+func batchTokens(items []BatchItem) int {
+	tokens := 0
+	for _, item := range items {
+		tokens += estimateTokens(item.Key) + estimateTokens(item.Text)
+	}
+	return tokens
+}
+
+// splitBatches groups items so that the source text of each batch stays
+// within maxTokens and batchMaxItems. An item larger than the budget gets a
+// batch of its own.
+func splitBatches(items []BatchItem, maxTokens int) [][]BatchItem {
+	var batches [][]BatchItem
+	var current []BatchItem
+	tokens := 0
+	for _, item := range items {
+		n := estimateTokens(item.Key) + estimateTokens(item.Text)
+		if len(current) > 0 && (tokens+n > maxTokens || len(current) >= batchMaxItems) {
+			batches = append(batches, current)
+			current, tokens = nil, 0
+		}
+		current = append(current, item)
+		tokens += n
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+// extractJSON strips Markdown code fences and any text around the outermost
+// JSON object of a model response.
+func extractJSON(s string) string {
+	start := strings.Index(s, "{")
+	end := strings.LastIndex(s, "}")
+	if start < 0 || end < start {
+		return s
+	}
+	return s[start : end+1]
+}
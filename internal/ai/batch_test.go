@@ -0,0 +1,230 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+)
+
+// stubModel is a chatModel that answers batch prompts with answer, which gets
+// the number of the call and the decoded JSON object of the prompt.
+type stubModel struct {
+	mu     sync.Mutex
+	calls  [][]string // 每次调用收到的键
+	answer func(call int, input map[string]string) (string, error)
+}
+
+func (m *stubModel) chat(ctx context.Context, system, prompt string) (string, error) {
+	var input map[string]string
+	if err := json.Unmarshal([]byte(extractJSON(prompt)), &input); err != nil {
+		return "", fmt.Errorf("prompt has no JSON object: %v", err)
+	}
+	keys := make([]string, 0, len(input))
+	for key := range input {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	m.mu.Lock()
+	call := len(m.calls)
+	m.calls = append(m.calls, keys)
+	m.mu.Unlock()
+	return m.answer(call, input)
+}
+
+// prefixed answers with every value prefixed with "T:", leaving out the keys
+// in skip.
+func prefixed(input map[string]string, skip ...string) (string, error) {
+	output := make(map[string]string, len(input))
+	for key, text := range input {
+		output[key] = "T:" + text
+	}
+	for _, key := range skip {
+		delete(output, key)
+	}
+	data, err := json.Marshal(output)
+	return "```json\n" + string(data) + "\n```", err
+}
+
+// useTranslator makes getTranslator return tr and configures a single worker
+// without retries and translation memory.
+func useTranslator(t *testing.T, tr Translator) {
+	t.Helper()
+	cfg := config.GetConfig()
+	saved := *cfg
+	cfg.TMDisabled = true
+	cfg.MaxRetries = -1
+	cfg.Concurrency = 1
+	cfg.GlossaryFile = filepath.Join(t.TempDir(), "glossary.json")
+	t.Cleanup(func() {
+		*cfg = saved
+		ResetTranslator()
+	})
+
+	ResetTranslator()
+	translatorOnce.Do(func() { translator = tr })
+}
+
+func TestSplitBatches(t *testing.T) {
+	item := func(key, text string) BatchItem { return BatchItem{Key: key, Text: text} }
+	// "k" 与四个字符的 ASCII 文本各估算为 1 个 token，中文每字 1 个 token
+	items := []BatchItem{item("a", "abcd"), item("b", "abcd"), item("c", "十个中文字符十个中文"), item("d", "abcd"), item("e", "abcd")}
+
+	tests := []struct {
+		name      string
+		items     []BatchItem
+		maxTokens int
+		want      [][]string
+	}{
+		{name: "one batch", items: items, maxTokens: 1000, want: [][]string{{"a", "b", "c", "d", "e"}}},
+		{name: "budget", items: items, maxTokens: 6, want: [][]string{{"a", "b"}, {"c"}, {"d", "e"}}},
+		{name: "item over budget", items: items[2:3], maxTokens: 2, want: [][]string{{"c"}}},
+		{name: "empty", items: nil, maxTokens: 10, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			for _, batch := range splitBatches(tt.items, tt.maxTokens) {
+				var keys []string
+				for _, item := range batch {
+					keys = append(keys, item.Key)
+				}
+				got = append(got, keys)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	many := make([]BatchItem, batchMaxItems+10)
+	for i := range many {
+		many[i] = item(fmt.Sprint(i), "a")
+	}
+	batches := splitBatches(many, 1<<20)
+	if len(batches) != 2 || len(batches[0]) != batchMaxItems || len(batches[1]) != 10 {
+		t.Errorf("%d items split into batches of %d, want %d and 10", len(many), batchLengths(batches), batchMaxItems)
+	}
+}
+
+func batchLengths(batches [][]BatchItem) []int {
+	lengths := make([]int, len(batches))
+	for i, b := range batches {
+		lengths[i] = len(b)
+	}
+	return lengths
+}
+
+func TestTranslateBatch(t *testing.T) {
+	req := BatchRequest{
+		SourceLang: "zh",
+		TargetLang: "en",
+		Items: []BatchItem{
+			{Key: "a", Text: "保存"},
+			{Key: "b", Text: "删除{0}"},
+			{Key: "c", Text: "<b>取消</b>"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		answer  func(call int, input map[string]string) (string, error)
+		calls   [][]string
+		want    map[string]string
+		failed  []string
+		wantErr string
+	}{
+		{
+			name:   "all items",
+			answer: func(call int, input map[string]string) (string, error) { return prefixed(input) },
+			calls:  [][]string{{"a", "b", "c"}},
+			want:   map[string]string{"a": "T:保存", "b": "T:删除{0}", "c": "T:<b>取消</b>"},
+		},
+		{
+			name: "missing item is batched again",
+			answer: func(call int, input map[string]string) (string, error) {
+				if call == 0 {
+					return prefixed(input, "b")
+				}
+				return prefixed(input)
+			},
+			calls: [][]string{{"a", "b", "c"}, {"b"}},
+			want:  map[string]string{"a": "T:保存", "b": "T:删除{0}", "c": "T:<b>取消</b>"},
+		},
+		{
+			name: "broken placeholder is batched again",
+			answer: func(call int, input map[string]string) (string, error) {
+				if call == 0 {
+					input["b"] = "删除"
+				}
+				return prefixed(input)
+			},
+			calls: [][]string{{"a", "b", "c"}, {"b"}},
+			want:  map[string]string{"a": "T:保存", "b": "T:删除{0}", "c": "T:<b>取消</b>"},
+		},
+		{
+			name: "failed request is batched again",
+			answer: func(call int, input map[string]string) (string, error) {
+				if call == 0 {
+					return "", errors.New("connection reset")
+				}
+				return prefixed(input)
+			},
+			calls: [][]string{{"a", "b", "c"}, {"a", "b", "c"}},
+			want:  map[string]string{"a": "T:保存", "b": "T:删除{0}", "c": "T:<b>取消</b>"},
+		},
+		{
+			name: "item that never comes back",
+			answer: func(call int, input map[string]string) (string, error) {
+				return prefixed(input, "c")
+			},
+			calls:   [][]string{{"a", "b", "c"}, {"c"}, {"c"}},
+			want:    map[string]string{"a": "T:保存", "b": "T:删除{0}"},
+			failed:  []string{"c"},
+			wantErr: "1个条目翻译失败\n  c: 响应中缺少该条目",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &stubModel{answer: tt.answer}
+			useTranslator(t, &chatTranslator{model: model})
+
+			got, err := TranslateBatch(req)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("results = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(model.calls, tt.calls) {
+				t.Errorf("calls = %v, want %v", model.calls, tt.calls)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var batchErr *BatchError
+			if !errors.As(err, &batchErr) {
+				t.Fatalf("err = %v, want a *BatchError", err)
+			}
+			var failed []string
+			for key := range batchErr.Failed {
+				failed = append(failed, key)
+			}
+			if !reflect.DeepEqual(failed, tt.failed) {
+				t.Errorf("failed = %q, want %q", failed, tt.failed)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %q, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package ai
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+)
+
+const defaultConcurrency = 4
+
+var (
+	throttleOnce sync.Once
+	slots        chan struct{} // 限制同时进行的请求数
+	requestLimit *bucket
+	tokenLimit   *bucket
+)
+
+func concurrency(cfg *config.Config) int {
+	if cfg.Concurrency > 0 {
+		return cfg.Concurrency
+	}
+	return defaultConcurrency
+}
+
+func initThrottle() {
+	cfg := config.GetConfig()
+	slots = make(chan struct{}, concurrency(cfg))
+	if cfg.RequestsPerMinute > 0 {
+		requestLimit = newBucket(cfg.RequestsPerMinute)
+	}
+	if cfg.TokensPerMinute > 0 {
+		tokenLimit = newBucket(cfg.TokensPerMinute)
+	}
+}
+
+// acquire waits until a request estimated at tokens may be sent, honouring
+// the worker count and the per-minute limits shared by all workers. The
+// returned function releases the worker slot.
+func acquire(ctx context.Context, tokens int) (func(), error) {
+	throttleOnce.Do(initThrottle)
+
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-slots }
+
+	if err := requestLimit.wait(ctx, 1); err != nil {
+		release()
+		return nil, err
+	}
+	if err := tokenLimit.wait(ctx, tokens); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// bucket is a token bucket refilled continuously at perMinute per minute.
+type bucket struct {
+	mu        sync.Mutex
+	capacity  float64
+	available float64
+	last      time.Time
+}
+
+func newBucket(perMinute int) *bucket {
+	return &bucket{
+		capacity:  float64(perMinute),
+		available: float64(perMinute),
+		last:      time.Now(),
+	}
+}
+
+// wait blocks until n units are available and takes them. A nil bucket never
+// blocks.
+func (b *bucket) wait(ctx context.Context, n int) error {
+	if b == nil {
+		return nil
+	}
+
+	need := float64(n)
+	if need > b.capacity {
+		need = b.capacity
+	}
+
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.available += now.Sub(b.last).Minutes() * b.capacity
+		if b.available > b.capacity {
+			b.available = b.capacity
+		}
+		b.last = now
+
+		if b.available >= need {
+			b.available -= need
+			b.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((need - b.available) / b.capacity * float64(time.Minute))
+		b.mu.Unlock()
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// ForEach calls fn for every index in [0, n) using the configured number of
+// workers and returns when all calls have finished. Requests made by fn share
+// the same worker slots and rate limits.
+func ForEach(n int, fn func(i int)) {
+	workers := concurrency(config.GetConfig())
+	if workers > n {
+		workers = n
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// TranslateAll translates every request concurrently. Results and errors are
+// returned in the order of reqs regardless of completion order.
+func TranslateAll(reqs []TranslationRequest) ([]string, []error) {
+	results := make([]string, len(reqs))
+	errs := make([]error, len(reqs))
+	ForEach(len(reqs), func(i int) {
+		results[i], errs[i] = Translate(reqs[i])
+	})
+	return results, errs
+}
@@ -0,0 +1,121 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+)
+
+const (
+	deeplDefaultURL     = "https://api.deepl.com/v2"
+	deeplFreeDefaultURL = "https://api-free.deepl.com/v2"
+)
+
+func init() {
+	Register("deepl", newDeepL)
+}
+
+// deepL talks to DeepL-style machine translation REST APIs. Unlike the chat
+// providers it translates text directly, without a prompt.
+type deepL struct {
+	url    string
+	apiKey string
+}
+
+func newDeepL(cfg *config.Config) (Translator, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("API密钥未设置。请运行:\ni18n-manager config --set-api-key YOUR_API_KEY")
+	}
+
+	// DeepL 免费版的密钥以 ":fx" 结尾，使用单独的域名
+	defaultURL := deeplDefaultURL
+	if strings.HasSuffix(cfg.APIKey, ":fx") {
+		defaultURL = deeplFreeDefaultURL
+	}
+	return &deepL{
+		url:    endpoint(cfg, defaultURL, "/translate"),
+		apiKey: cfg.APIKey,
+	}, nil
+}
+
+type deeplRequest struct {
+	Text       []string `json:"text"`
+	SourceLang string   `json:"source_lang,omitempty"`
+	TargetLang string   `json:"target_lang"`
+}
+
+type deeplResponse struct {
+	Translations []struct {
+		Text string `json:"text"`
+	} `json:"translations"`
+}
+
+// deeplLang converts a language code such as "zh_TW" to DeepL's notation.
+func deeplLang(code string, target bool) string {
+	code = strings.ToUpper(strings.ReplaceAll(code, "_", "-"))
+	if !target {
+		// 源语言只接受不带地区的代码
+		code, _, _ = strings.Cut(code, "-")
+		return code
+	}
+	switch code {
+	case "ZH", "ZH-CN":
+		return "ZH-HANS"
+	case "ZH-TW", "ZH-HK":
+		return "ZH-HANT"
+	case "EN":
+		return "EN-US"
+	}
+	return code
+}
+
+func (t *deepL) translate(ctx context.Context, texts []string, sourceLang, targetLang string) ([]string, error) {
+	request := deeplRequest{
+		Text:       texts,
+		SourceLang: deeplLang(sourceLang, false),
+		TargetLang: deeplLang(targetLang, true),
+	}
+	headers := map[string]string{"Authorization": "DeepL-Auth-Key " + t.apiKey}
+
+	var resp deeplResponse
+	if err := postJSON(ctx, t.url, headers, request, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Translations) != len(texts) {
+		return nil, fmt.Errorf("响应中的翻译数量不匹配：期望%d个，实际%d个", len(texts), len(resp.Translations))
+	}
+
+	results := make([]string, len(texts))
+	for i, tr := range resp.Translations {
+		results[i] = tr.Text
+	}
+	return results, nil
+}
+
+func (t *deepL) Translate(ctx context.Context, req TranslationRequest) (string, error) {
+	results, err := t.translate(ctx, []string{req.Text}, req.SourceLang, req.TargetLang)
+	if err != nil {
+		return "", err
+	}
+	return results[0], nil
+}
+
+func (t *deepL) TranslateBatch(ctx context.Context, req BatchRequest) (map[string]string, error) {
+	texts := make([]string, len(req.Items))
+	for i, item := range req.Items {
+		texts[i] = item.Text
+	}
+
+	results, err := t.translate(ctx, texts, req.SourceLang, req.TargetLang)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make(map[string]string, len(results))
+	for i, item := range req.Items {
+		output[item.Key] = results[i]
+	}
+	return output, nil
+}
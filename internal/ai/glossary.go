@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/SimonGino/i18n-manager/internal/glossary"
+)
+
+var glossaryWarning sync.Once
+
+// glossaryTerms returns the glossary terms that occur in text. A glossary
+// that cannot be loaded is reported once and otherwise ignored.
+func glossaryTerms(text, sourceLang, targetLang string) []glossary.Term {
+	g, err := glossary.Default()
+	if err != nil {
+		glossaryWarning.Do(func() {
+			fmt.Printf("警告: 无法加载术语表，本次不使用术语表: %v\n", err)
+		})
+		return nil
+	}
+	return g.Match(text, sourceLang, targetLang)
+}
+
+// glossaryPrompt lists the required term translations. It is placed in front
+// of the prompt so that the text to translate stays at the end.
+func glossaryPrompt(terms []glossary.Term) string {
+	if len(terms) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("必须使用以下术语译法：\n")
+	for _, t := range terms {
+		if t.Target == "" {
+			fmt.Fprintf(&b, "%s => %s（保持原样，不要翻译）\n", t.Source, t.Source)
+		} else {
+			fmt.Fprintf(&b, "%s => %s\n", t.Source, t.Target)
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// batchGlossaryPrompt lists the glossary terms used by any item of req.
+func batchGlossaryPrompt(req BatchRequest) string {
+	seen := make(map[string]bool)
+	var terms []glossary.Term
+	for _, item := range req.Items {
+		for _, t := range glossaryTerms(item.Text, req.SourceLang, req.TargetLang) {
+			if !seen[t.Source] {
+				seen[t.Source] = true
+				terms = append(terms, t)
+			}
+		}
+	}
+	return glossaryPrompt(terms)
+}
+
+// GlossaryViolations returns the glossary terms of req whose required
+// translation is missing from result.
+func GlossaryViolations(req TranslationRequest, result string) []glossary.Term {
+	return glossary.Violations(glossaryTerms(req.Text, req.SourceLang, req.TargetLang), result)
+}
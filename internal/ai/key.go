@@ -0,0 +1,77 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const keySystemPrompt = "你是一位Java国际化专家，负责为properties文件中的消息命名。只返回键名，不要包含任何解释。"
+
+// KeyRequest asks for a message key for a new text.
+type KeyRequest struct {
+	Text       string
+	SourceLang string
+	English    string // 英文译文，可以为空
+	Prefix     string // 键必须使用的前缀，可以为空
+}
+
+// KeySuggester is implemented by providers that can propose a semantic key
+// for a text.
+type KeySuggester interface {
+	SuggestKey(ctx context.Context, req KeyRequest) (string, error)
+}
+
+// keyPrompt 构建生成键的提示信息
+func keyPrompt(req KeyRequest) string {
+	var b strings.Builder
+	b.WriteString("为以下消息生成一个简短、表达语义的properties键。键由小写英文单词组成，用点(.)分隔层级，")
+	b.WriteString("第一段表示类别，例如 error、success、info、label、button、title、msg、validation，")
+	b.WriteString("同一段中的多个单词用下划线(_)连接，例如 error.user.not_found。\n")
+	if req.Prefix != "" {
+		fmt.Fprintf(&b, "键必须以 %s 开头。\n", req.Prefix)
+	}
+	fmt.Fprintf(&b, "消息（%s）：%s\n", req.SourceLang, req.Text)
+	if req.English != "" && req.SourceLang != "en" {
+		fmt.Fprintf(&b, "英文译文：%s\n", req.English)
+	}
+	return b.String()
+}
+
+func (t *chatTranslator) SuggestKey(ctx context.Context, req KeyRequest) (string, error) {
+	return t.model.chat(ctx, keySystemPrompt, keyPrompt(req))
+}
+
+// SuggestKey asks the configured provider for a message key. Only the first
+// line of the answer is used, with quotes and backticks removed; the caller
+// is responsible for validating it.
+func SuggestKey(req KeyRequest) (string, error) {
+	t, err := getTranslator()
+	if err != nil {
+		return "", err
+	}
+	ks, ok := t.(KeySuggester)
+	if !ok {
+		return "", fmt.Errorf("当前翻译服务不支持生成键")
+	}
+
+	var result string
+	err = withRetry(2*estimateTokens(keySystemPrompt+keyPrompt(req)), func(ctx context.Context) error {
+		var err error
+		result, err = ks.SuggestKey(ctx, req)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	result = strings.TrimSpace(result)
+	if i := strings.IndexByte(result, '\n'); i >= 0 {
+		result = result[:i]
+	}
+	result = strings.Trim(strings.TrimSpace(result), "`\"'")
+	if result == "" {
+		return "", fmt.Errorf("响应中没有生成的键")
+	}
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package ai
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/SimonGino/i18n-manager/internal/placeholder"
+	"github.com/SimonGino/i18n-manager/internal/tm"
+)
+
+var memoryWarning sync.Once
+
+// ModelID identifies the configured provider and model in the translation
+// memory, for example "openai/gpt-4o".
+func ModelID() string {
+	cfg := config.GetConfig()
+	if cfg.Model == "" {
+		return providerName(cfg)
+	}
+	return providerName(cfg) + "/" + cfg.Model
+}
+
+// memory returns the translation memory, or nil when it is disabled, the
+// mock provider is used or it cannot be loaded. A broken memory never stops a
+// translation.
+func memory() *tm.Memory {
+	cfg := config.GetConfig()
+	// mock 的结果只用于测试，既不读取也不写入翻译记忆库
+	if cfg.TMDisabled || providerName(cfg) == "mock" {
+		return nil
+	}
+	m, err := tm.Default()
+	if err != nil {
+		memoryWarning.Do(func() {
+			fmt.Printf("警告: 无法加载翻译记忆库，本次不使用缓存: %v\n", err)
+		})
+		return nil
+	}
+	return m
+}
+
+// lookupMemory returns the translation remembered for exactly req.Text. It is
+// validated like a fresh one and treated as a miss if its placeholders differ
+// from req.Text or it ignores a glossary term.
+func lookupMemory(req TranslationRequest) (string, bool) {
+	m := memory()
+	if m == nil {
+		return "", false
+	}
+	entry, ok := m.Lookup(req.Text, req.SourceLang, req.TargetLang, ModelID())
+	if !ok || placeholder.Validate(req.Text, entry.Target) != nil || len(GlossaryViolations(req, entry.Target)) > 0 {
+		return "", false
+	}
+	return entry.Target, true
+}
+
+// maxReferences limits the similar translations passed to the model for one
+// text.
+const maxReferences = 3
+
+// memoryReferences returns earlier translations of texts similar to req.Text
+// when tm_fuzzy_threshold is configured. They are passed to the model as
+// context only, since they translate a different text.
+func memoryReferences(req TranslationRequest) []Reference {
+	threshold := config.GetConfig().TMFuzzyThreshold
+	m := memory()
+	if m == nil || threshold <= 0 {
+		return nil
+	}
+	var refs []Reference
+	for _, match := range m.Similar(req.Text, req.SourceLang, req.TargetLang, ModelID(), threshold, maxReferences) {
+		refs = append(refs, maskReference(match.Source, match.Target))
+	}
+	return refs
+}
+
+// remember stores a fresh AI translation in the translation memory, unless
+// it ignores a glossary term.
+func remember(req TranslationRequest, result string) {
+	if len(GlossaryViolations(req, result)) > 0 {
+		return
+	}
+	if m := memory(); m != nil {
+		m.Put(tm.Entry{
+			Source:     req.Text,
+			SourceLang: req.SourceLang,
+			TargetLang: req.TargetLang,
+			Model:      ModelID(),
+			Target:     result,
+		})
+	}
+}
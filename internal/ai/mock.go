@@ -0,0 +1,79 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+)
+
+func init() {
+	Register("mock", newMock)
+}
+
+// mockTranslator is an offline provider for tests and CI. It answers from a
+// fixture file when one is configured and otherwise returns a predictable
+// pseudo-translation of the form "[en] 原文".
+type mockTranslator struct {
+	// 目标语言 -> 原文 -> 译文
+	fixture map[string]map[string]string
+}
+
+func newMock(cfg *config.Config) (Translator, error) {
+	m := &mockTranslator{}
+	if cfg.MockFixture == "" {
+		return m, nil
+	}
+
+	data, err := os.ReadFile(cfg.MockFixture)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取模拟翻译文件: %v", err)
+	}
+	if err := json.Unmarshal(data, &m.fixture); err != nil {
+		return nil, fmt.Errorf("无法解析模拟翻译文件 %s: %v", cfg.MockFixture, err)
+	}
+
+	// 翻译服务收到的是占位符被替换为 ⟦n⟧ 的文本，这里用同样的方式处理文件中的原文和译文
+	for lang, texts := range m.fixture {
+		if lang == "key" {
+			continue
+		}
+		masked := make(map[string]string, len(texts))
+		for source, target := range texts {
+			r := maskReference(source, target)
+			masked[r.Source] = r.Target
+		}
+		m.fixture[lang] = masked
+	}
+	return m, nil
+}
+
+func (m *mockTranslator) translate(text, targetLang string) string {
+	if translated, ok := m.fixture[targetLang][text]; ok {
+		return translated
+	}
+	return fmt.Sprintf("[%s] %s", targetLang, text)
+}
+
+func (m *mockTranslator) Translate(ctx context.Context, req TranslationRequest) (string, error) {
+	return m.translate(req.Text, req.TargetLang), nil
+}
+
+func (m *mockTranslator) TranslateBatch(ctx context.Context, req BatchRequest) (map[string]string, error) {
+	results := make(map[string]string, len(req.Items))
+	for _, item := range req.Items {
+		results[item.Key] = m.translate(item.Text, req.TargetLang)
+	}
+	return results, nil
+}
+
+// SuggestKey answers from the "key" section of the fixture, keyed by the
+// source text, and otherwise derives a key from the text.
+func (m *mockTranslator) SuggestKey(ctx context.Context, req KeyRequest) (string, error) {
+	if key, ok := m.fixture["key"][req.Text]; ok {
+		return key, nil
+	}
+	return fmt.Sprintf("%smock.%d", req.Prefix, len([]rune(req.Text))), nil
+}
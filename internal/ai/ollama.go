@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+)
+
+const ollamaDefaultURL = "http://localhost:11434"
+
+func init() {
+	Register("ollama", newOllama)
+}
+
+// ollamaModel talks to the native chat API of a local Ollama server.
+type ollamaModel struct {
+	url   string
+	model string
+}
+
+func newOllama(cfg *config.Config) (Translator, error) {
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("AI模型未设置。请运行:\ni18n-manager config --set-model MODEL_NAME")
+	}
+	return &chatTranslator{model: &ollamaModel{
+		url:   endpoint(cfg, ollamaDefaultURL, "/api/chat"),
+		model: cfg.Model,
+	}}, nil
+}
+
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ollamaRequest struct {
+	Model    string                 `json:"model"`
+	Messages []ollamaMessage        `json:"messages"`
+	Stream   bool                   `json:"stream"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+}
+
+type ollamaResponse struct {
+	Message ollamaMessage `json:"message"`
+}
+
+func (m *ollamaModel) chat(ctx context.Context, system, prompt string) (string, error) {
+	request := ollamaRequest{
+		Model: m.model,
+		Messages: []ollamaMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: prompt},
+		},
+		Options: map[string]interface{}{"temperature": 0.3},
+	}
+
+	var resp ollamaResponse
+	if err := postJSON(ctx, m.url, nil, request, &resp); err != nil {
+		return "", err
+	}
+	return resp.Message.Content, nil
+}
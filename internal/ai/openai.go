@@ -0,0 +1,151 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/sashabaranov/go-openai"
+)
+
+const llamaCppDefaultURL = "http://localhost:8080/v1"
+
+func init() {
+	Register("openai", newOpenAI)
+	Register("azure", newOpenAI)
+	Register("llamacpp", newLlamaCpp)
+}
+
+// openAIModel talks to OpenAI-compatible chat completion APIs, including
+// Azure OpenAI and local servers such as llama.cpp or LM Studio.
+type openAIModel struct {
+	client *openai.Client
+	model  string
+}
+
+func newOpenAI(cfg *config.Config) (Translator, error) {
+	// 检查API密钥是否设置
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("API密钥未设置。请运行:\ni18n-manager config --set-api-key YOUR_API_KEY")
+	}
+
+	// 检查API URL是否设置
+	if cfg.APIURL == "" {
+		return nil, fmt.Errorf("API URL未设置。请运行:\ni18n-manager config --set-api-url YOUR_API_URL")
+	}
+
+	// 检查模型是否设置
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("AI模型未设置。请运行:\ni18n-manager config --set-model MODEL_NAME")
+	}
+
+	// 创建自定义配置
+	clientConfig := openai.DefaultConfig(cfg.APIKey)
+	clientConfig.BaseURL = cfg.APIURL
+	clientConfig.HTTPClient = &http.Client{Transport: retryAfterTransport{http.DefaultTransport}}
+
+	// 如果是Azure OpenAI，设置API版本
+	if providerName(cfg) == "azure" {
+		if cfg.AzureAPIVersion == "" {
+			return nil, fmt.Errorf("Azure API版本未设置。请运行:\ni18n-manager config --set-azure-api-version VERSION")
+		}
+		clientConfig.APIVersion = cfg.AzureAPIVersion
+		clientConfig.APIType = openai.APITypeAzure
+	}
+
+	return &chatTranslator{model: &openAIModel{
+		client: openai.NewClientWithConfig(clientConfig),
+		model:  cfg.Model,
+	}}, nil
+}
+
+// newLlamaCpp creates a client for a local llama.cpp server, which exposes
+// an OpenAI-compatible API and does not need an API key.
+func newLlamaCpp(cfg *config.Config) (Translator, error) {
+	local := *cfg
+	if local.APIURL == "" {
+		local.APIURL = llamaCppDefaultURL
+	}
+	if local.APIKey == "" {
+		local.APIKey = "none"
+	}
+	if local.Model == "" {
+		local.Model = "default"
+	}
+	return newOpenAI(&local)
+}
+
+func (m *openAIModel) chat(ctx context.Context, system, prompt string) (string, error) {
+	// 创建请求
+	request := openai.ChatCompletionRequest{
+		Model: m.model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: system,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: prompt,
+			},
+		},
+		Temperature: 0.3, // 较低的温度使输出更确定
+	}
+
+	var retryAfter time.Duration
+	ctx = context.WithValue(ctx, retryAfterKey{}, &retryAfter)
+
+	resp, err := m.client.CreateChatCompletion(ctx, request)
+	if err != nil {
+		return "", openAIError(err, retryAfter)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("响应中没有翻译结果")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// openAIError converts go-openai errors into *StatusError.
+func openAIError(err error, retryAfter time.Duration) error {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) && apiErr.HTTPStatusCode > 0 {
+		// 额度用尽同样返回 429，但重试无济于事
+		code, _ := apiErr.Code.(string)
+		return &StatusError{
+			StatusCode: apiErr.HTTPStatusCode,
+			RetryAfter: retryAfter,
+			Permanent:  code == "insufficient_quota",
+			Err:        err,
+		}
+	}
+
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) && reqErr.HTTPStatusCode > 0 {
+		return &StatusError{StatusCode: reqErr.HTTPStatusCode, RetryAfter: retryAfter, Err: err}
+	}
+	return err
+}
+
+type retryAfterKey struct{}
+
+// retryAfterTransport records the Retry-After header of throttled responses
+// into the *time.Duration stored in the request context, since go-openai does
+// not expose response headers on errors.
+type retryAfterTransport struct {
+	base http.RoundTripper
+}
+
+func (t retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if d, ok := req.Context().Value(retryAfterKey{}).(*time.Duration); ok {
+		*d = parseRetryAfter(resp.Header.Get("Retry-After"))
+	}
+	return resp, nil
+}
@@ -0,0 +1,16 @@
+package ai
+
+import "github.com/SimonGino/i18n-manager/internal/placeholder"
+
+// restorePlaceholders puts the masked placeholders back into a translation
+// and rejects it unless it has exactly the placeholders of the source text.
+func restorePlaceholders(source, translated string, placeholders []string) (string, error) {
+	restored, err := placeholder.Unmask(translated, placeholders)
+	if err != nil {
+		return "", err
+	}
+	if err := placeholder.Validate(source, restored); err != nil {
+		return "", err
+	}
+	return restored, nil
+}
@@ -0,0 +1,204 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+)
+
+// Translator translates text with a particular AI or machine translation
+// provider. Implementations make a single attempt; retries, timeouts and
+// rate limiting are handled by the caller.
+type Translator interface {
+	Translate(ctx context.Context, req TranslationRequest) (string, error)
+}
+
+// BatchTranslator is implemented by providers that can translate several
+// keyed strings in one request.
+type BatchTranslator interface {
+	TranslateBatch(ctx context.Context, req BatchRequest) (map[string]string, error)
+}
+
+// Factory creates a Translator from the configuration.
+type Factory func(cfg *config.Config) (Translator, error)
+
+var providers = make(map[string]Factory)
+
+// Register makes a provider available under name for the "provider" setting.
+func Register(name string, factory Factory) {
+	providers[name] = factory
+}
+
+// Providers returns the names of all registered providers.
+func Providers() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// providerName returns the configured provider. Older configurations without
+// a provider use Azure when an Azure API version is set, OpenAI otherwise.
+func providerName(cfg *config.Config) string {
+	if cfg.Provider != "" {
+		return cfg.Provider
+	}
+	if cfg.AzureAPIVersion != "" {
+		return "azure"
+	}
+	return "openai"
+}
+
+var (
+	translatorOnce sync.Once
+	translator     Translator
+	translatorErr  error
+)
+
+// getTranslator returns the Translator selected by the configuration.
+func getTranslator() (Translator, error) {
+	translatorOnce.Do(func() {
+		cfg := config.GetConfig()
+		name := providerName(cfg)
+		factory, ok := providers[name]
+		if !ok {
+			translatorErr = fmt.Errorf("未知的翻译服务: %s（可用: %s）", name, strings.Join(Providers(), ", "))
+			return
+		}
+		translator, translatorErr = factory(cfg)
+	})
+	return translator, translatorErr
+}
+
+// ResetTranslator discards the translator created by getTranslator, so that
+// the next translation creates one from the current configuration.
+func ResetTranslator() {
+	translatorOnce = sync.Once{}
+	translator, translatorErr = nil, nil
+}
+
+// StatusError is an HTTP error response from a provider.
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // 服务端要求的重试等待时间
+	Permanent  bool          // 即使状态码可重试也不应重试，如额度用尽
+	Err        error
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("status %d: %v", e.StatusCode, e.Err)
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// endpoint joins the configured API URL, or the provider default, with path.
+func endpoint(cfg *config.Config, defaultURL, path string) string {
+	base := cfg.APIURL
+	if base == "" {
+		base = defaultURL
+	}
+	base = strings.TrimRight(base, "/")
+	if strings.HasSuffix(base, path) {
+		return base
+	}
+	return base + path
+}
+
+// postJSON sends in as a JSON request and decodes the response into out.
+// Non-2xx responses are returned as *StatusError.
+func postJSON(ctx context.Context, url string, headers map[string]string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("构建请求失败: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("构建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message := strings.TrimSpace(string(data))
+		if len(message) > 500 {
+			message = message[:500]
+		}
+		return &StatusError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+			Err:        fmt.Errorf("%s", message),
+		}
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("无法解析响应: %v", err)
+	}
+	return nil
+}
+
+// chatModel is a provider that answers a system and a user prompt, as large
+// language models do.
+type chatModel interface {
+	chat(ctx context.Context, system, prompt string) (string, error)
+}
+
+// chatTranslator turns a chatModel into a Translator by prompting it.
+type chatTranslator struct {
+	model chatModel
+}
+
+func (t *chatTranslator) Translate(ctx context.Context, req TranslationRequest) (string, error) {
+	return t.model.chat(ctx, translateSystemPrompt, translatePrompt(req))
+}
+
+// TranslateBatch sends the items as one JSON object and decodes the JSON
+// object returned by the model.
+func (t *chatTranslator) TranslateBatch(ctx context.Context, req BatchRequest) (map[string]string, error) {
+	input := make(map[string]string, len(req.Items))
+	for _, item := range req.Items {
+		input[item.Key] = item.Text
+	}
+	data, err := json.MarshalIndent(input, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("构建请求失败: %v", err)
+	}
+
+	prompt := batchGlossaryPrompt(req) + referencePrompt(req.References) + fmt.Sprintf("将以下JSON对象中的值从%s翻译为%s：\n%s", req.SourceLang, req.TargetLang, data)
+	content, err := t.model.chat(ctx, batchSystemPrompt, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	var output map[string]string
+	if err := json.Unmarshal([]byte(extractJSON(content)), &output); err != nil {
+		return nil, fmt.Errorf("无法解析响应中的JSON: %v", err)
+	}
+	return output, nil
+}
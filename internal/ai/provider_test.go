@@ -0,0 +1,332 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+)
+
+// recordedRequest is a request received by a test server.
+type recordedRequest struct {
+	Path   string
+	Header http.Header
+	Body   map[string]interface{}
+}
+
+// newTestServer starts a server that records every request and answers with
+// status, headers and body.
+func newTestServer(t *testing.T, status int, headers map[string]string, body string) (*httptest.Server, *[]recordedRequest) {
+	t.Helper()
+	var requests []recordedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("reading request body: %v", err)
+		}
+		rec := recordedRequest{Path: r.URL.Path, Header: r.Header.Clone()}
+		if err := json.Unmarshal(data, &rec.Body); err != nil {
+			t.Errorf("request body is not JSON: %v\n%s", err, data)
+		}
+		requests = append(requests, rec)
+
+		w.Header().Set("Content-Type", "application/json")
+		for name, value := range headers {
+			w.Header().Set(name, value)
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func newTestTranslator(t *testing.T, provider string, cfg *config.Config) Translator {
+	t.Helper()
+	translator, err := providers[provider](cfg)
+	if err != nil {
+		t.Fatalf("creating %s translator: %v", provider, err)
+	}
+	return translator
+}
+
+// lastRequest returns the only request received by a test server.
+func lastRequest(t *testing.T, requests *[]recordedRequest) recordedRequest {
+	t.Helper()
+	if len(*requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(*requests))
+	}
+	return (*requests)[0]
+}
+
+// field returns the value at a path of object keys and array indexes in a
+// decoded JSON body.
+func field(body interface{}, path ...interface{}) interface{} {
+	for _, p := range path {
+		switch p := p.(type) {
+		case string:
+			m, _ := body.(map[string]interface{})
+			body = m[p]
+		case int:
+			a, _ := body.([]interface{})
+			if p >= len(a) {
+				return nil
+			}
+			body = a[p]
+		}
+	}
+	return body
+}
+
+// checkStatusError checks that err is a *StatusError with the given status
+// code and Retry-After delay.
+func checkStatusError(t *testing.T, err error, status int, retryAfter time.Duration) *StatusError {
+	t.Helper()
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("error = %v (%T), want *StatusError", err, err)
+	}
+	if statusErr.StatusCode != status {
+		t.Errorf("StatusCode = %d, want %d", statusErr.StatusCode, status)
+	}
+	if statusErr.RetryAfter != retryAfter {
+		t.Errorf("RetryAfter = %v, want %v", statusErr.RetryAfter, retryAfter)
+	}
+	return statusErr
+}
+
+var testRequest = TranslationRequest{Text: "保存", SourceLang: "zh", TargetLang: "en"}
+
+func TestAnthropic(t *testing.T) {
+	srv, requests := newTestServer(t, http.StatusOK, nil,
+		`{"content":[{"type":"text","text":"Save"},{"type":"tool_use","id":"x"},{"type":"text","text":" file"}]}`)
+	translator := newTestTranslator(t, "anthropic", &config.Config{APIURL: srv.URL + "/v1/", APIKey: "key", Model: "claude-test"})
+
+	got, err := translator.Translate(context.Background(), testRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "Save file" {
+		t.Errorf("Translate = %q, want %q", got, "Save file")
+	}
+
+	req := lastRequest(t, requests)
+	if req.Path != "/v1/messages" {
+		t.Errorf("path = %q, want /v1/messages", req.Path)
+	}
+	if got := req.Header.Get("x-api-key"); got != "key" {
+		t.Errorf("x-api-key = %q, want key", got)
+	}
+	if got := req.Header.Get("anthropic-version"); got != anthropicVersion {
+		t.Errorf("anthropic-version = %q, want %q", got, anthropicVersion)
+	}
+	if got := field(req.Body, "model"); got != "claude-test" {
+		t.Errorf("model = %v, want claude-test", got)
+	}
+	if got := field(req.Body, "max_tokens"); got != float64(anthropicMaxTokens) {
+		t.Errorf("max_tokens = %v, want %d", got, anthropicMaxTokens)
+	}
+	if got := field(req.Body, "system"); got != translateSystemPrompt {
+		t.Errorf("system = %v, want the translate system prompt", got)
+	}
+	if got := field(req.Body, "messages", 0, "role"); got != "user" {
+		t.Errorf("messages[0].role = %v, want user", got)
+	}
+	if got, _ := field(req.Body, "messages", 0, "content").(string); !strings.Contains(got, "保存") {
+		t.Errorf("messages[0].content = %q, want the text to translate", got)
+	}
+}
+
+func TestAnthropicError(t *testing.T) {
+	srv, _ := newTestServer(t, http.StatusTooManyRequests, map[string]string{"Retry-After": "7"},
+		`{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`)
+	translator := newTestTranslator(t, "anthropic", &config.Config{APIURL: srv.URL, APIKey: "key", Model: "claude-test"})
+
+	_, err := translator.Translate(context.Background(), testRequest)
+	statusErr := checkStatusError(t, err, http.StatusTooManyRequests, 7*time.Second)
+	if !strings.Contains(statusErr.Error(), "slow down") {
+		t.Errorf("error = %q, want the response body", statusErr.Error())
+	}
+}
+
+func TestChatBatch(t *testing.T) {
+	srv, requests := newTestServer(t, http.StatusOK, nil,
+		`{"content":[{"type":"text","text":"Here you go:\n`+"```json"+`\n{\"a\": \"Save\", \"b\": \"Delete ⟦0⟧\"}\n`+"```"+`"}]}`)
+	translator := newTestTranslator(t, "anthropic", &config.Config{APIURL: srv.URL, APIKey: "key", Model: "claude-test"})
+
+	got, err := translator.(BatchTranslator).TranslateBatch(context.Background(), BatchRequest{
+		Items:      []BatchItem{{Key: "a", Text: "保存"}, {Key: "b", Text: "删除⟦0⟧"}},
+		SourceLang: "zh",
+		TargetLang: "en",
+		References: []Reference{{Source: "删除文件", Target: "Delete file"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["a"] != "Save" || got["b"] != "Delete ⟦0⟧" || len(got) != 2 {
+		t.Errorf("TranslateBatch = %v", got)
+	}
+
+	req := lastRequest(t, requests)
+	if got := field(req.Body, "system"); got != batchSystemPrompt {
+		t.Errorf("system = %v, want the batch system prompt", got)
+	}
+	prompt, _ := field(req.Body, "messages", 0, "content").(string)
+	for _, want := range []string{`"a": "保存"`, `"b": "删除⟦0⟧"`, "删除文件 => Delete file"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt does not contain %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestDeepL(t *testing.T) {
+	srv, requests := newTestServer(t, http.StatusOK, nil,
+		`{"translations":[{"detected_source_language":"ZH","text":"儲存"},{"detected_source_language":"ZH","text":"刪除"}]}`)
+	translator := newTestTranslator(t, "deepl", &config.Config{APIURL: srv.URL + "/v2", APIKey: "key:fx"})
+
+	got, err := translator.(BatchTranslator).TranslateBatch(context.Background(), BatchRequest{
+		Items:      []BatchItem{{Key: "a", Text: "保存"}, {Key: "b", Text: "删除"}},
+		SourceLang: "zh_CN",
+		TargetLang: "zh_TW",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["a"] != "儲存" || got["b"] != "刪除" || len(got) != 2 {
+		t.Errorf("TranslateBatch = %v", got)
+	}
+
+	req := lastRequest(t, requests)
+	if req.Path != "/v2/translate" {
+		t.Errorf("path = %q, want /v2/translate", req.Path)
+	}
+	if got := req.Header.Get("Authorization"); got != "DeepL-Auth-Key key:fx" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if got := field(req.Body, "source_lang"); got != "ZH" {
+		t.Errorf("source_lang = %v, want ZH", got)
+	}
+	if got := field(req.Body, "target_lang"); got != "ZH-HANT" {
+		t.Errorf("target_lang = %v, want ZH-HANT", got)
+	}
+	if field(req.Body, "text", 0) != "保存" || field(req.Body, "text", 1) != "删除" {
+		t.Errorf("text = %v, want [保存 删除]", field(req.Body, "text"))
+	}
+}
+
+func TestDeepLCountMismatch(t *testing.T) {
+	srv, _ := newTestServer(t, http.StatusOK, nil, `{"translations":[{"text":"Save"}]}`)
+	translator := newTestTranslator(t, "deepl", &config.Config{APIURL: srv.URL, APIKey: "key"})
+
+	_, err := translator.(BatchTranslator).TranslateBatch(context.Background(), BatchRequest{
+		Items:      []BatchItem{{Key: "a", Text: "保存"}, {Key: "b", Text: "删除"}},
+		SourceLang: "zh",
+		TargetLang: "en",
+	})
+	if err == nil || !strings.Contains(err.Error(), "数量不匹配") {
+		t.Errorf("error = %v, want a count mismatch", err)
+	}
+}
+
+func TestDeepLError(t *testing.T) {
+	srv, _ := newTestServer(t, http.StatusServiceUnavailable, map[string]string{"Retry-After": "3"}, `{"message":"busy"}`)
+	translator := newTestTranslator(t, "deepl", &config.Config{APIURL: srv.URL, APIKey: "key"})
+
+	_, err := translator.Translate(context.Background(), testRequest)
+	checkStatusError(t, err, http.StatusServiceUnavailable, 3*time.Second)
+}
+
+func TestOllama(t *testing.T) {
+	srv, requests := newTestServer(t, http.StatusOK, nil,
+		`{"model":"llama3","message":{"role":"assistant","content":"Save"},"done":true}`)
+	translator := newTestTranslator(t, "ollama", &config.Config{APIURL: srv.URL, Model: "llama3"})
+
+	got, err := translator.Translate(context.Background(), testRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "Save" {
+		t.Errorf("Translate = %q, want Save", got)
+	}
+
+	req := lastRequest(t, requests)
+	if req.Path != "/api/chat" {
+		t.Errorf("path = %q, want /api/chat", req.Path)
+	}
+	if got := field(req.Body, "model"); got != "llama3" {
+		t.Errorf("model = %v, want llama3", got)
+	}
+	if got := field(req.Body, "stream"); got != false {
+		t.Errorf("stream = %v, want false", got)
+	}
+	if field(req.Body, "messages", 0, "role") != "system" || field(req.Body, "messages", 1, "role") != "user" {
+		t.Errorf("messages = %v, want a system and a user message", field(req.Body, "messages"))
+	}
+}
+
+func TestOllamaError(t *testing.T) {
+	srv, _ := newTestServer(t, http.StatusNotFound, nil, `{"error":"model \"llama3\" not found"}`)
+	translator := newTestTranslator(t, "ollama", &config.Config{APIURL: srv.URL, Model: "llama3"})
+
+	_, err := translator.Translate(context.Background(), testRequest)
+	checkStatusError(t, err, http.StatusNotFound, 0)
+}
+
+func TestOpenAI(t *testing.T) {
+	srv, requests := newTestServer(t, http.StatusOK, nil,
+		`{"id":"1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"Save"},"finish_reason":"stop"}]}`)
+	translator := newTestTranslator(t, "openai", &config.Config{APIURL: srv.URL + "/v1", APIKey: "key", Model: "gpt-test"})
+
+	got, err := translator.Translate(context.Background(), testRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "Save" {
+		t.Errorf("Translate = %q, want Save", got)
+	}
+
+	req := lastRequest(t, requests)
+	if req.Path != "/v1/chat/completions" {
+		t.Errorf("path = %q, want /v1/chat/completions", req.Path)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer key" {
+		t.Errorf("Authorization = %q, want Bearer key", got)
+	}
+	if got := field(req.Body, "model"); got != "gpt-test" {
+		t.Errorf("model = %v, want gpt-test", got)
+	}
+	if field(req.Body, "messages", 0, "role") != "system" || field(req.Body, "messages", 1, "role") != "user" {
+		t.Errorf("messages = %v, want a system and a user message", field(req.Body, "messages"))
+	}
+}
+
+func TestOpenAIError(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		permanent bool
+	}{
+		{name: "rate limited", code: "rate_limit_exceeded", permanent: false},
+		{name: "quota exhausted", code: "insufficient_quota", permanent: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := newTestServer(t, http.StatusTooManyRequests, map[string]string{"Retry-After": "5"},
+				`{"error":{"message":"too many requests","type":"requests","code":"`+tt.code+`"}}`)
+			translator := newTestTranslator(t, "openai", &config.Config{APIURL: srv.URL, APIKey: "key", Model: "gpt-test"})
+
+			_, err := translator.Translate(context.Background(), testRequest)
+			statusErr := checkStatusError(t, err, http.StatusTooManyRequests, 5*time.Second)
+			if statusErr.Permanent != tt.permanent {
+				t.Errorf("Permanent = %v, want %v", statusErr.Permanent, tt.permanent)
+			}
+		})
+	}
+}
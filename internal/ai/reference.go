@@ -0,0 +1,42 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SimonGino/i18n-manager/internal/placeholder"
+)
+
+// Reference is an earlier translation of a similar text, shown to the model
+// so that it stays consistent with existing wording.
+type Reference struct {
+	Source string
+	Target string
+}
+
+// maskReference masks the placeholders of a reference the way the text to
+// translate is masked, so the model never sees raw placeholders it might
+// copy into its answer.
+func maskReference(source, target string) Reference {
+	masked, placeholders := placeholder.Mask(source)
+	for i, p := range placeholders {
+		target = strings.Replace(target, p, placeholder.Token(i), 1)
+	}
+	return Reference{Source: masked, Target: target}
+}
+
+// referencePrompt lists the reference translations. Like the glossary it is
+// placed in front of the prompt so that the text to translate stays at the
+// end.
+func referencePrompt(refs []Reference) string {
+	if len(refs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("以下是相似文本的已有译文，仅供参考，请保持用词一致，但必须按照待翻译文本的实际内容翻译：\n")
+	for _, r := range refs {
+		fmt.Fprintf(&b, "%s => %s\n", r.Source, r.Target)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,142 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultTimeout    = 30 * time.Second
+	baseBackoff       = time.Second
+	maxBackoff        = 30 * time.Second
+	maxRetryAfter     = 2 * time.Minute
+)
+
+// sleep waits between attempts; tests replace it to run without delays.
+var sleep = time.Sleep
+
+func maxRetries(cfg *config.Config) int {
+	if cfg.MaxRetries < 0 {
+		return 0
+	}
+	if cfg.MaxRetries == 0 {
+		return defaultMaxRetries
+	}
+	return cfg.MaxRetries
+}
+
+func requestTimeout(cfg *config.Config) time.Duration {
+	if cfg.TimeoutSeconds > 0 {
+		return time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	return defaultTimeout
+}
+
+// withRetry calls fn until it succeeds, fails with a fatal error or the
+// configured number of retries is used up. Every attempt waits for a worker
+// slot and the rate limiter and runs with the configured timeout.
+func withRetry(tokens int, fn func(ctx context.Context) error) error {
+	cfg := config.GetConfig()
+	retries := maxRetries(cfg)
+	for attempt := 0; ; attempt++ {
+		err := attemptOnce(cfg, tokens, fn)
+		if err == nil {
+			return nil
+		}
+
+		var statusErr *StatusError
+		isStatus := errors.As(err, &statusErr)
+		if !retryable(err) {
+			if isStatus {
+				return fmt.Errorf("API请求失败: %v\n请检查您的API密钥、模型名称和配额。", err)
+			}
+			return err
+		}
+		if attempt >= retries {
+			return fmt.Errorf("API请求失败（已重试%d次）: %v\n请检查您的配额和网络连接。", retries, err)
+		}
+
+		var retryAfter time.Duration
+		if isStatus {
+			retryAfter = statusErr.RetryAfter
+		}
+		sleep(backoff(attempt, retryAfter))
+	}
+}
+
+func attemptOnce(cfg *config.Config, tokens int, fn func(ctx context.Context) error) error {
+	// 等待并发槽位和速率限制
+	release, err := acquire(context.Background(), tokens)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout(cfg))
+	defer cancel()
+	return fn(ctx)
+}
+
+// retryable reports whether err is a transient failure, such as a rate limit,
+// a server error or a timeout. Authentication errors, unknown models and
+// other client errors are fatal.
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return !statusErr.Permanent && retryableStatus(statusErr.StatusCode)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500
+}
+
+// backoff returns the delay before retry number attempt (starting at 0). A
+// Retry-After value sent by the server takes precedence; otherwise the delay
+// grows exponentially with random jitter.
+func backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		if retryAfter > maxRetryAfter {
+			return maxRetryAfter
+		}
+		return retryAfter
+	}
+
+	d := baseBackoff << uint(attempt)
+	if d <= 0 || d > maxBackoff {
+		d = maxBackoff
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,130 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+)
+
+func TestWithRetry(t *testing.T) {
+	status := func(code int) error {
+		return &StatusError{StatusCode: code, Err: errors.New(http.StatusText(code))}
+	}
+
+	tests := []struct {
+		name    string
+		errs    []error // 依次返回的错误，用完后成功
+		calls   int
+		sleeps  []time.Duration // 0 表示随机退避
+		wantErr string
+	}{
+		{name: "success", calls: 1},
+		{name: "rate limited", errs: []error{status(429)}, calls: 2, sleeps: []time.Duration{0}},
+		{name: "server errors", errs: []error{status(500), status(502)}, calls: 3, sleeps: []time.Duration{0, 0}},
+		{name: "timeout", errs: []error{context.DeadlineExceeded}, calls: 2, sleeps: []time.Duration{0}},
+		{
+			name:   "retry after",
+			errs:   []error{&StatusError{StatusCode: 429, RetryAfter: 5 * time.Second, Err: errors.New("slow down")}},
+			calls:  2,
+			sleeps: []time.Duration{5 * time.Second},
+		},
+		{
+			name:   "retry after is capped",
+			errs:   []error{&StatusError{StatusCode: 503, RetryAfter: time.Hour, Err: errors.New("maintenance")}},
+			calls:  2,
+			sleeps: []time.Duration{maxRetryAfter},
+		},
+		{
+			name:    "retries used up",
+			errs:    []error{status(503), status(503), status(503), status(503)},
+			calls:   4,
+			sleeps:  []time.Duration{0, 0, 0},
+			wantErr: "已重试3次",
+		},
+		{name: "unauthorized", errs: []error{status(401)}, calls: 1, wantErr: "请检查您的API密钥"},
+		{name: "unknown model", errs: []error{status(404)}, calls: 1, wantErr: "status 404"},
+		{
+			name:    "quota exhausted",
+			errs:    []error{&StatusError{StatusCode: 429, Permanent: true, Err: errors.New("insufficient_quota")}},
+			calls:   1,
+			wantErr: "insufficient_quota",
+		},
+		{name: "other error", errs: []error{errors.New("invalid response")}, calls: 1, wantErr: "invalid response"},
+	}
+
+	cfg := config.GetConfig()
+	saved := *cfg
+	t.Cleanup(func() {
+		*cfg = saved
+		sleep = time.Sleep
+	})
+	cfg.MaxRetries = 3
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sleeps []time.Duration
+			sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+			calls := 0
+			err := withRetry(1, func(ctx context.Context) error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+
+			if calls != tt.calls {
+				t.Errorf("calls = %d, want %d", calls, tt.calls)
+			}
+			if len(sleeps) != len(tt.sleeps) {
+				t.Fatalf("sleeps = %v, want %d", sleeps, len(tt.sleeps))
+			}
+			for i, d := range sleeps {
+				want := tt.sleeps[i]
+				if want == 0 {
+					// 指数退避: 第 i 次重试等待 base<<i 的一半到全部
+					max := baseBackoff << uint(i)
+					if d < max/2 || d > max {
+						t.Errorf("sleep %d = %v, want between %v and %v", i, d, max/2, max)
+					}
+				} else if d != want {
+					t.Errorf("sleep %d = %v, want %v", i, d, want)
+				}
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":         0,
+		"7":        7 * time.Second,
+		"0":        0,
+		"-3":       0,
+		"tomorrow": 0,
+		time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat): 0,
+	}
+	for value, want := range tests {
+		if got := parseRetryAfter(value); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", value, got, want)
+		}
+	}
+
+	future := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(future); got <= 50*time.Second || got > time.Minute {
+		t.Errorf("parseRetryAfter(%q) = %v, want about a minute", future, got)
+	}
+}
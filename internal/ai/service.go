@@ -3,85 +3,71 @@ package ai
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"strings"
-	"time"
 
-	"github.com/SimonGino/i18n-manager/internal/config"
-	"github.com/sashabaranov/go-openai"
+	"github.com/SimonGino/i18n-manager/internal/placeholder"
 )
 
+const translateSystemPrompt = "你是一位专业翻译。只返回翻译后的文本，不要包含任何解释。" + placeholderPrompt
+
+// placeholderPrompt explains the tokens that replace placeholders such as {0}
+// and %s while a text is translated.
+const placeholderPrompt = "文本中形如⟦0⟧的标记是占位符，必须原样保留，可以根据语序调整位置。"
+
 type TranslationRequest struct {
 	Text       string
 	SourceLang string
 	TargetLang string
+	References []Reference // 翻译记忆库中相似文本的译文，仅作参考
 }
 
-func Translate(req TranslationRequest) (string, error) {
-	cfg := config.GetConfig()
-
-	// 检查API密钥是否设置
-	if cfg.APIKey == "" {
-		return "", fmt.Errorf("API密钥未设置。请运行:\ni18n-manager config --set-api-key YOUR_API_KEY")
-	}
-
-	// 检查API URL是否设置
-	if cfg.APIURL == "" {
-		return "", fmt.Errorf("API URL未设置。请运行:\ni18n-manager config --set-api-url YOUR_API_URL")
-	}
-
-	// 检查模型是否设置
-	if cfg.Model == "" {
-		return "", fmt.Errorf("AI模型未设置。请运行:\ni18n-manager config --set-model MODEL_NAME")
-	}
-
-	// 创建自定义配置
-	clientConfig := openai.DefaultConfig(cfg.APIKey)
-	clientConfig.BaseURL = cfg.APIURL
-	clientConfig.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+// translatePrompt 构建单条翻译的提示信息，并附加文本中出现的术语和参考译文
+func translatePrompt(req TranslationRequest) string {
+	return glossaryPrompt(glossaryTerms(req.Text, req.SourceLang, req.TargetLang)) +
+		referencePrompt(req.References) +
+		fmt.Sprintf("将以下文本从%s翻译为%s。只返回翻译后的文本，不要包含任何解释或额外内容：\n%s",
+			req.SourceLang, req.TargetLang, req.Text)
+}
 
-	// 如果是Azure OpenAI，设置API版本
-	if cfg.AzureAPIVersion != "" {
-		clientConfig.APIVersion = cfg.AzureAPIVersion
-		clientConfig.APIType = openai.APITypeAzure
+// Translate translates a single text, reusing the translation memory when it
+// already knows the answer and passing it translations of similar texts as
+// references otherwise. Placeholders are masked before the text is sent
+// and a translation that changes them is rejected.
+func Translate(req TranslationRequest) (string, error) {
+	if result, ok := lookupMemory(req); ok {
+		return result, nil
 	}
 
-	// 创建OpenAI客户端
-	client := openai.NewClientWithConfig(clientConfig)
-
-	// 构建提示信息
-	prompt := fmt.Sprintf("将以下文本从%s翻译为%s。只返回翻译后的文本，不要包含任何解释或额外内容：\n%s",
-		req.SourceLang, req.TargetLang, req.Text)
-
-	// 创建请求
-	request := openai.ChatCompletionRequest{
-		Model: cfg.Model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleSystem,
-				Content: "你是一位专业翻译。只返回翻译后的文本，不要包含任何解释。",
-			},
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		},
-		Temperature: 0.3, // 较低的温度使输出更确定
+	t, err := getTranslator()
+	if err != nil {
+		return "", err
 	}
 
-	// 发送请求
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	resp, err := client.CreateChatCompletion(ctx, request)
+	masked := req
+	var placeholders []string
+	masked.Text, placeholders = placeholder.Mask(req.Text)
+	masked.References = memoryReferences(req)
+
+	var result string
+	tokens := estimateTokens(translateSystemPrompt + translatePrompt(masked))
+	err = withRetry(2*tokens, func(ctx context.Context) error {
+		var err error
+		result, err = t.Translate(ctx, masked)
+		return err
+	})
 	if err != nil {
-		return "", fmt.Errorf("API请求失败: %v\n请检查您的API密钥、配额和网络连接。", err)
+		return "", err
 	}
 
-	if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == "" {
+	result = strings.TrimSpace(result)
+	if result == "" {
 		return "", fmt.Errorf("响应中没有翻译结果")
 	}
+	if result, err = restorePlaceholders(req.Text, result, placeholders); err != nil {
+		return "", err
+	}
+	remember(req, result)
 
 	// 返回翻译结果
-	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+	return result, nil
 }
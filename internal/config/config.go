@@ -27,10 +27,64 @@ type Config struct {
 	Model          string         `json:"model"`
 	DefaultPath    string         `json:"default_path"`
 	Language       LanguageConfig `json:"language"`
+	// 翻译服务：openai、azure、anthropic、deepl、ollama、llamacpp、mock，默认为 openai
+	Provider string `json:"provider,omitempty"`
+	// mock 服务使用的固定译文文件（目标语言 -> 原文 -> 译文）
+	MockFixture string `json:"mock_fixture,omitempty"`
 	// Azure OpenAI specific fields
 	AzureAPIVersion string `json:"azure_api_version,omitempty"`
+	// 保留的备份数量，0 表示使用默认值，负数表示关闭备份
+	MaxBackups int `json:"max_backups,omitempty"`
+	// 批量翻译时每个请求的最大估算 token 数，0 表示使用默认值
+	BatchMaxTokens int `json:"batch_max_tokens,omitempty"`
+	// 并发翻译的工作协程数，0 表示使用默认值
+	Concurrency int `json:"concurrency,omitempty"`
+	// 所有工作协程共享的每分钟请求数和 token 数上限，0 表示不限制
+	RequestsPerMinute int `json:"requests_per_minute,omitempty"`
+	TokensPerMinute   int `json:"tokens_per_minute,omitempty"`
+	// 临时性错误的最大重试次数，0 表示使用默认值，负数表示不重试
+	MaxRetries int `json:"max_retries,omitempty"`
+	// 单个请求的超时时间（秒），0 表示使用默认值
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// 关闭翻译记忆库
+	TMDisabled bool `json:"tm_disabled,omitempty"`
+	// 相似文本的译文作为参考提供给模型的最低相似度（0-1），0 表示不提供参考
+	TMFuzzyThreshold float64 `json:"tm_fuzzy_threshold,omitempty"`
+	// 术语表文件（.json 或 .csv），默认为项目目录下的 .i18n-manager/glossary.json
+	GlossaryFile string `json:"glossary_file,omitempty"`
+	// MessageFormat 单引号约定：args（默认，只有带参数的值需要双写单引号）、always（所有值都需要）、off（不检查）
+	Apostrophes string `json:"apostrophes,omitempty"`
+	// 保存翻译时按约定自动双写单引号，而不是只给出警告
+	EscapeApostrophes bool `json:"escape_apostrophes,omitempty"`
+	// lint 规则配置：规则名 -> 严重级别和参数
+	Lint map[string]LintRule `json:"lint,omitempty"`
+	// extract --rewrite 替换硬编码字符串时使用的表达式，%s 为生成的键
+	ExtractExpression string `json:"extract_expression,omitempty"`
+	// 自动生成键的方式：dotted（默认）、snake、camel、ai、hash
+	KeyStyle string `json:"key_style,omitempty"`
+	// 生成的键的前缀，未设置时为 msg.，设为空字符串表示不加前缀
+	KeyPrefix *string `json:"key_prefix,omitempty"`
+	// extract 按源码目录使用的键前缀：相对于 --src 的目录 -> 前缀，优先于 key_prefix
+	KeyModulePrefixes map[string]string `json:"key_module_prefixes,omitempty"`
+	// 键中由文本生成的部分的最大长度（字节），0 表示使用默认值 50
+	KeyMaxLength int `json:"key_max_length,omitempty"`
+	// 生成键时去掉 the、a、of 等常见英文虚词
+	KeyRemoveStopWords bool `json:"key_remove_stop_words,omitempty"`
+	// 生成键时额外去掉的单词
+	KeyStopWords []string `json:"key_stop_words,omitempty"`
+	// 识别相近原文的最低相似度（0-1），0 表示使用默认值 0.9
+	DuplicateThreshold float64 `json:"duplicate_threshold,omitempty"`
 }
 
+// LintRule 配置 lint 的单条规则，未设置的字段使用规则的默认值
+type LintRule struct {
+	Severity string `json:"severity,omitempty"` // error、warning 或 off
+	Pattern  string `json:"pattern,omitempty"`  // key-pattern 规则使用的正则表达式
+	Max      int    `json:"max,omitempty"`      // key-depth 和 max-length 规则的上限
+}
+
+const defaultAPIURL = "https://api.openai.com/v1/chat/completions"
+
 var currentConfig *Config
 
 func init() {
@@ -56,6 +110,11 @@ func getConfigPath() string {
 	return filepath.Join(configDir, "i18n-manager")
 }
 
+// GetConfigDir 返回配置目录，翻译记忆库等数据也保存在该目录下
+func GetConfigDir() string {
+	return getConfigPath()
+}
+
 func getConfigFilePath() string {
 	return filepath.Join(getConfigPath(), "config.json")
 }
@@ -73,8 +132,8 @@ func loadConfig() {
 		// 默认配置
 		currentConfig = &Config{
 			DefaultPath: ".",
-			APIURL:      "https://api.openai.com/v1/chat/completions", // 默认使用OpenAI API完整路径
-			Model:       "gpt-3.5-turbo",                              // 默认模型
+			APIURL:      defaultAPIURL,   // 默认使用OpenAI API完整路径
+			Model:       "gpt-3.5-turbo", // 默认模型
 			Language: LanguageConfig{
 				FilePattern: "message-application%s.properties",
 				Default:     "", // 英文文件没有后缀
@@ -136,6 +195,19 @@ func HandleConfig(c *cli.Context) error {
 		return nil
 	}
 
+	if provider := c.String("set-provider"); provider != "" {
+		currentConfig.Provider = provider
+		// 切换到其他服务时清除默认的 OpenAI 地址，使用该服务自己的默认地址
+		if provider != "openai" && provider != "azure" && currentConfig.APIURL == defaultAPIURL {
+			currentConfig.APIURL = ""
+		}
+		if err := saveConfig(); err != nil {
+			return fmt.Errorf("failed to save provider: %v", err)
+		}
+		fmt.Println("Provider updated successfully")
+		return nil
+	}
+
 	if model := c.String("set-model"); model != "" {
 		currentConfig.Model = model
 		if err := saveConfig(); err != nil {
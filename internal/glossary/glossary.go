@@ -0,0 +1,252 @@
+// Package glossary manages the project terms that must always be translated
+// the same way, such as product names and brand names.
+package glossary
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+)
+
+// DefaultFile is used when glossary_file is not configured. Like backups it
+// lives in the project so that it can be committed with the bundles.
+const DefaultFile = ".i18n-manager/glossary.json"
+
+var csvHeader = []string{"source_lang", "target_lang", "source", "target"}
+
+// Term is the required translation of Source from SourceLang to TargetLang.
+// An empty TargetLang applies to every target language and an empty Target
+// means the term must be kept unchanged, as brand names are.
+type Term struct {
+	SourceLang string `json:"source_lang"`
+	TargetLang string `json:"target_lang,omitempty"`
+	Source     string `json:"source"`
+	Target     string `json:"target,omitempty"`
+}
+
+// Translation returns the text the term must be translated to.
+func (t Term) Translation() string {
+	if t.Target == "" {
+		return t.Source
+	}
+	return t.Target
+}
+
+// Glossary is a list of terms stored as JSON or, if the file name ends in
+// .csv, as CSV with the columns source_lang, target_lang, source, target.
+type Glossary struct {
+	path  string
+	Terms []Term
+}
+
+var (
+	defaultOnce     sync.Once
+	defaultGlossary *Glossary
+	defaultErr      error
+)
+
+// Path returns the configured glossary file.
+func Path() string {
+	if file := config.GetConfig().GlossaryFile; file != "" {
+		return file
+	}
+	return DefaultFile
+}
+
+// Default returns the project glossary, loaded once.
+func Default() (*Glossary, error) {
+	defaultOnce.Do(func() {
+		defaultGlossary, defaultErr = Load(Path())
+	})
+	return defaultGlossary, defaultErr
+}
+
+// Load reads the glossary at path. A missing file yields an empty glossary.
+func Load(path string) (*Glossary, error) {
+	g := &Glossary{path: path}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return g, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading glossary: %v", err)
+	}
+	defer f.Close()
+
+	if isCSV(path) {
+		g.Terms, err = readCSV(f)
+	} else {
+		err = json.NewDecoder(f).Decode(&g.Terms)
+		if err == io.EOF {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing glossary %s: %v", path, err)
+	}
+	return g, nil
+}
+
+func isCSV(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".csv")
+}
+
+func readCSV(r io.Reader) ([]Term, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > 0 && strings.EqualFold(records[0][0], csvHeader[0]) {
+		records = records[1:]
+	}
+
+	terms := make([]Term, 0, len(records))
+	for i, record := range records {
+		if len(record) != len(csvHeader) {
+			return nil, fmt.Errorf("line %d: expected %d columns, got %d", i+2, len(csvHeader), len(record))
+		}
+		terms = append(terms, Term{SourceLang: record[0], TargetLang: record[1], Source: record[2], Target: record[3]})
+	}
+	return terms, nil
+}
+
+// Save writes the glossary back to its file, sorted by language pair and term.
+func (g *Glossary) Save() error {
+	sort.SliceStable(g.Terms, func(i, j int) bool {
+		a, b := g.Terms[i], g.Terms[j]
+		if a.SourceLang != b.SourceLang {
+			return a.SourceLang < b.SourceLang
+		}
+		if a.TargetLang != b.TargetLang {
+			return a.TargetLang < b.TargetLang
+		}
+		return a.Source < b.Source
+	})
+
+	var data []byte
+	if isCSV(g.path) {
+		var b strings.Builder
+		w := csv.NewWriter(&b)
+		w.Write(csvHeader)
+		for _, t := range g.Terms {
+			w.Write([]string{t.SourceLang, t.TargetLang, t.Source, t.Target})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return fmt.Errorf("error writing glossary: %v", err)
+		}
+		data = []byte(b.String())
+	} else {
+		var err error
+		if data, err = json.MarshalIndent(g.Terms, "", "  "); err != nil {
+			return fmt.Errorf("error marshaling glossary: %v", err)
+		}
+		data = append(data, '\n')
+	}
+
+	if err := os.MkdirAll(filepath.Dir(g.path), 0755); err != nil {
+		return fmt.Errorf("error creating glossary directory: %v", err)
+	}
+	if err := os.WriteFile(g.path, data, 0644); err != nil {
+		return fmt.Errorf("error writing glossary: %v", err)
+	}
+	return nil
+}
+
+// Add stores term, replacing the term with the same source text and language
+// pair. It reports whether a term was replaced.
+func (g *Glossary) Add(term Term) bool {
+	for i, t := range g.Terms {
+		if t.Source == term.Source && t.SourceLang == term.SourceLang && t.TargetLang == term.TargetLang {
+			g.Terms[i] = term
+			return true
+		}
+	}
+	g.Terms = append(g.Terms, term)
+	return false
+}
+
+// Remove deletes the terms for source. An empty targetLang removes the term
+// from every language pair. It returns the number of removed terms.
+func (g *Glossary) Remove(source, targetLang string) int {
+	kept := g.Terms[:0]
+	for _, t := range g.Terms {
+		if t.Source == source && (targetLang == "" || t.TargetLang == targetLang) {
+			continue
+		}
+		kept = append(kept, t)
+	}
+	removed := len(g.Terms) - len(kept)
+	g.Terms = kept
+	return removed
+}
+
+// Match returns the terms of the language pair that occur in text, longest
+// first. A term contained in a longer matching term is left out, so that
+// "空间" does not compete with "工作空间". Terms for a specific target language
+// take precedence over terms for every language.
+func (g *Glossary) Match(text, sourceLang, targetLang string) []Term {
+	bySource := make(map[string]Term)
+	for _, t := range g.Terms {
+		if t.SourceLang != sourceLang || (t.TargetLang != "" && t.TargetLang != targetLang) {
+			continue
+		}
+		if !containsFold(text, t.Source) {
+			continue
+		}
+		if existing, ok := bySource[t.Source]; ok && existing.TargetLang != "" {
+			continue
+		}
+		bySource[t.Source] = t
+	}
+
+	terms := make([]Term, 0, len(bySource))
+	for _, t := range bySource {
+		terms = append(terms, t)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if len(terms[i].Source) != len(terms[j].Source) {
+			return len(terms[i].Source) > len(terms[j].Source)
+		}
+		return terms[i].Source < terms[j].Source
+	})
+
+	var result []Term
+	for _, t := range terms {
+		nested := false
+		for _, longer := range result {
+			if containsFold(longer.Source, t.Source) {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// Violations returns the terms whose required translation does not appear in
+// translated.
+func Violations(terms []Term, translated string) []Term {
+	var violations []Term
+	for _, t := range terms {
+		if !containsFold(translated, t.Translation()) {
+			violations = append(violations, t)
+		}
+	}
+	return violations
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -0,0 +1,42 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/SimonGino/i18n-manager/internal/placeholder"
+)
+
+// usesMessageFormat reports whether value is formatted by MessageFormat under
+// the configured apostrophes convention. Spring only does so for messages
+// with arguments unless alwaysUseMessageFormat is set.
+func usesMessageFormat(value string) bool {
+	switch config.GetConfig().Apostrophes {
+	case "off":
+		return false
+	case "always":
+		return true
+	default:
+		return placeholder.HasArguments(value)
+	}
+}
+
+// hasUnescapedApostrophes reports whether MessageFormat would drop single
+// quotes of value.
+func hasUnescapedApostrophes(value string) bool {
+	return usesMessageFormat(value) && len(placeholder.UnescapedApostrophes(value)) > 0
+}
+
+// prepareValue applies the apostrophes convention to a value about to be
+// written: it doubles single quotes if escape_apostrophes is set and warns
+// otherwise.
+func prepareValue(lang, key, value string) string {
+	if !hasUnescapedApostrophes(value) {
+		return value
+	}
+	if config.GetConfig().EscapeApostrophes {
+		return placeholder.EscapeApostrophes(value)
+	}
+	fmt.Printf("Warning: %s value of '%s' has single quotes that MessageFormat will drop, double them ('') or run 'i18n-manager lint --fix'\n", lang, key)
+	return value
+}
@@ -0,0 +1,322 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	backupRoot        = ".i18n-manager/backups"
+	backupManifest    = "manifest.json"
+	backupTimeLayout  = "20060102-150405"
+	defaultMaxBackups = 10
+)
+
+// backupFile records one file captured by a backup.
+type backupFile struct {
+	Path    string `json:"path"`    // 原始文件的绝对路径
+	Stored  string `json:"stored"`  // 备份目录内的相对路径
+	Existed bool   `json:"existed"` // 备份时文件是否存在
+}
+
+type backupInfo struct {
+	ID      string       `json:"id"`
+	Created time.Time    `json:"created"`
+	Files   []backupFile `json:"files"`
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory and
+// renames it over filename, so readers never observe a partially written file.
+func writeFileAtomic(filename string, data []byte) error {
+	tmp, err := createTempFile(filename, data)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error replacing %s: %v", filename, err)
+	}
+	return nil
+}
+
+// createTempFile writes data next to filename and returns the temp file path.
+// The temp file keeps the permissions of filename if it already exists.
+func createTempFile(filename string, data []byte) (string, error) {
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(filename); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("error creating directory %s: %v", dir, err)
+	}
+
+	file, err := os.CreateTemp(dir, "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("error creating temp file for %s: %v", filename, err)
+	}
+	tmp := file.Name()
+
+	fail := func(err error) (string, error) {
+		file.Close()
+		os.Remove(tmp)
+		return "", fmt.Errorf("error writing %s: %v", filename, err)
+	}
+
+	if _, err := file.Write(data); err != nil {
+		return fail(err)
+	}
+	if err := file.Sync(); err != nil {
+		return fail(err)
+	}
+	if err := file.Chmod(perm); err != nil {
+		return fail(err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("error writing %s: %v", filename, err)
+	}
+	return tmp, nil
+}
+
+// backupDir returns the backup directory as an absolute path.
+func backupDir() (string, error) {
+	dir, err := filepath.Abs(backupRoot)
+	if err != nil {
+		return "", fmt.Errorf("error resolving backup directory: %v", err)
+	}
+	return dir, nil
+}
+
+// createBackup copies the current content of files into a new backup
+// directory and prunes old backups. Files that do not exist yet are recorded
+// so that restoring removes them again. Paths are recorded as absolute paths,
+// so a restore writes back to the same files even if the working directory
+// has changed.
+func createBackup(files []string) (string, error) {
+	maxBackups := config.GetConfig().MaxBackups
+	if maxBackups < 0 {
+		return "", nil
+	}
+	if maxBackups == 0 {
+		maxBackups = defaultMaxBackups
+	}
+
+	root, err := backupDir()
+	if err != nil {
+		return "", err
+	}
+	id := time.Now().Format(backupTimeLayout)
+	dir := filepath.Join(root, id)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			break
+		}
+		id = fmt.Sprintf("%s-%d", time.Now().Format(backupTimeLayout), i)
+		dir = filepath.Join(root, id)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("error creating backup directory: %v", err)
+	}
+
+	info := backupInfo{ID: id, Created: time.Now()}
+	for i, filename := range files {
+		path, err := filepath.Abs(filename)
+		if err != nil {
+			return "", fmt.Errorf("error resolving %s: %v", filename, err)
+		}
+		entry := backupFile{
+			Path:   path,
+			Stored: fmt.Sprintf("%d_%s", i, filepath.Base(filename)),
+		}
+
+		data, err := os.ReadFile(filename)
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("error reading %s: %v", filename, err)
+		}
+		if err == nil {
+			entry.Existed = true
+			if err := os.WriteFile(filepath.Join(dir, entry.Stored), data, 0644); err != nil {
+				return "", fmt.Errorf("error backing up %s: %v", filename, err)
+			}
+		}
+		info.Files = append(info.Files, entry)
+	}
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error marshaling backup manifest: %v", err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, backupManifest), data); err != nil {
+		return "", err
+	}
+
+	if err := pruneBackups(maxBackups); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// listBackups returns all backups, oldest first.
+func listBackups() ([]backupInfo, error) {
+	root, err := backupDir()
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading backups: %v", err)
+	}
+
+	var backups []backupInfo
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(root, d.Name(), backupManifest))
+		if err != nil {
+			// 没有清单的目录是未完成的备份
+			continue
+		}
+		var info backupInfo
+		if err := json.Unmarshal(data, &info); err != nil {
+			continue
+		}
+		info.ID = d.Name()
+		backups = append(backups, info)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Created.Before(backups[j].Created)
+	})
+	return backups, nil
+}
+
+func pruneBackups(keep int) error {
+	root, err := backupDir()
+	if err != nil {
+		return err
+	}
+	backups, err := listBackups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > keep {
+		if err := os.RemoveAll(filepath.Join(root, backups[0].ID)); err != nil {
+			return fmt.Errorf("error removing old backup %s: %v", backups[0].ID, err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// readBackup loads every file recorded in a backup. Files that did not exist
+// when the backup was taken map to nil.
+func readBackup(info backupInfo) (map[string][]byte, error) {
+	root, err := backupDir()
+	if err != nil {
+		return nil, err
+	}
+	contents := make(map[string][]byte, len(info.Files))
+	for _, f := range info.Files {
+		if !f.Existed {
+			contents[f.Path] = nil
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(root, info.ID, f.Stored))
+		if err != nil {
+			return nil, fmt.Errorf("error reading backup of %s: %v", f.Path, err)
+		}
+		contents[f.Path] = data
+	}
+	return contents, nil
+}
+
+// restoreFiles writes back the given file contents, removing files whose
+// content is nil.
+func restoreFiles(contents map[string][]byte) error {
+	for path, data := range contents {
+		if data == nil {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("error removing %s: %v", path, err)
+			}
+			continue
+		}
+		if err := writeFileAtomic(path, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func HandleRestore(c *cli.Context) error {
+	backups, err := listBackups()
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		return fmt.Errorf("no backups found in %s", backupRoot)
+	}
+
+	if c.Bool("list") {
+		for _, b := range backups {
+			paths := make([]string, 0, len(b.Files))
+			for _, f := range b.Files {
+				paths = append(paths, f.Path)
+			}
+			fmt.Printf("%s  %s\n", b.ID, strings.Join(paths, ", "))
+		}
+		return nil
+	}
+
+	target := backups[len(backups)-1]
+	if id := c.String("id"); id != "" {
+		found := false
+		for _, b := range backups {
+			if b.ID == id {
+				target, found = b, true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("backup '%s' not found", id)
+		}
+	}
+
+	contents, err := readBackup(target)
+	if err != nil {
+		return err
+	}
+
+	// 恢复前先备份当前状态，以便撤销本次恢复
+	paths := make([]string, 0, len(target.Files))
+	for _, f := range target.Files {
+		paths = append(paths, f.Path)
+	}
+	current, err := createBackup(paths)
+	if err != nil {
+		return fmt.Errorf("error backing up current files: %v", err)
+	}
+
+	if err := restoreFiles(contents); err != nil {
+		return fmt.Errorf("error restoring backup %s: %v", target.ID, err)
+	}
+
+	fmt.Printf("Restored backup %s\n", target.ID)
+	if current != "" {
+		fmt.Printf("Previous state saved as backup %s\n", current)
+	}
+	return nil
+}
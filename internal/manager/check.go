@@ -0,0 +1,471 @@
+package manager
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/SimonGino/i18n-manager/internal/placeholder"
+	"github.com/urfave/cli/v2"
+)
+
+// Kinds of problems reported by check.
+const (
+	issueMissing     = "missing"
+	issuePlaceholder = "placeholder"
+	issueDuplicate   = "duplicate"
+)
+
+var issueKinds = []string{issueMissing, issuePlaceholder, issueDuplicate}
+
+var issueDescriptions = map[string]string{
+	issueMissing:     "Translation missing in a language file",
+	issuePlaceholder: "Placeholders differ from the source-language value",
+	issueDuplicate:   "Key defined more than once in the same file",
+}
+
+// checkIssue is one problem found by check. Line is 0 when the problem has no
+// line, such as a key missing from a file.
+type checkIssue struct {
+	Kind    string `json:"kind"`
+	Key     string `json:"key"`
+	Lang    string `json:"lang"`
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+	Failing bool   `json:"failing"`
+}
+
+// placeholderMismatch is a translation whose placeholders differ from the
+// source-language value.
+type placeholderMismatch struct {
+	Key     string
+	Lang    string
+	Missing []string
+	Extra   []string
+}
+
+func (m placeholderMismatch) String() string {
+	var parts []string
+	if len(m.Missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(m.Missing, " "))
+	}
+	if len(m.Extra) > 0 {
+		parts = append(parts, "unexpected "+strings.Join(m.Extra, " "))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// findPlaceholderMismatches compares the placeholder signature of every
+// translated value with the source-language value of the same key.
+func findPlaceholderMismatches(translations []Translation) []placeholderMismatch {
+	sourceLang := config.GetSourceLang()
+	if sourceLang == nil {
+		return nil
+	}
+
+	var mismatches []placeholderMismatch
+	for _, t := range translations {
+		source, ok := t.Values[sourceLang.Code]
+		if !ok {
+			continue
+		}
+		for _, mapping := range config.GetConfig().Language.Mappings {
+			value, ok := t.Values[mapping.Code]
+			if mapping.IsSource || !ok {
+				continue
+			}
+			missing, extra := placeholder.CompareSignatures(source, value)
+			if len(missing) > 0 || len(extra) > 0 {
+				mismatches = append(mismatches, placeholderMismatch{Key: t.Key, Lang: mapping.Code, Missing: missing, Extra: extra})
+			}
+		}
+	}
+	return mismatches
+}
+
+// duplicateKey is a key defined more than once in one file. Java uses the
+// last definition.
+type duplicateKey struct {
+	Key   string
+	Lang  string
+	Lines []int
+}
+
+// findDuplicates returns the keys defined more than once in a language file.
+func findDuplicates() ([]duplicateKey, error) {
+	var duplicates []duplicateKey
+	for _, mapping := range config.GetConfig().Language.Mappings {
+		doc, err := loadDocument(config.GetPropertiesFilePath(mapping.Code))
+		if err != nil {
+			return nil, err
+		}
+
+		lines := make(map[string][]int)
+		var order []string
+		for _, entry := range doc.Entries() {
+			if _, ok := lines[entry.Key]; !ok {
+				order = append(order, entry.Key)
+			}
+			lines[entry.Key] = append(lines[entry.Key], entry.Line)
+		}
+		for _, key := range order {
+			if len(lines[key]) > 1 {
+				duplicates = append(duplicates, duplicateKey{Key: key, Lang: mapping.Code, Lines: lines[key]})
+			}
+		}
+	}
+	return duplicates, nil
+}
+
+// parseFailOn parses the --fail-on selectors.
+func parseFailOn(selectors []string) (map[string]bool, error) {
+	failOn := make(map[string]bool)
+	for _, selector := range selectors {
+		for _, kind := range strings.Split(selector, ",") {
+			kind = strings.TrimSpace(kind)
+			if kind == "" {
+				continue
+			}
+			if _, ok := issueDescriptions[kind]; !ok {
+				return nil, fmt.Errorf("invalid --fail-on value '%s' (valid: %s)", kind, strings.Join(issueKinds, ", "))
+			}
+			failOn[kind] = true
+		}
+	}
+	if len(failOn) == 0 {
+		for _, kind := range issueKinds {
+			failOn[kind] = true
+		}
+	}
+	return failOn, nil
+}
+
+// collectIssues runs every check and returns the issues ordered by kind.
+func collectIssues(failOn map[string]bool) ([]checkIssue, error) {
+	translations, err := loadAllTranslations()
+	if err != nil {
+		return nil, fmt.Errorf("error loading translations: %v", err)
+	}
+	lines := make(map[string]map[string]int, len(translations))
+	for _, t := range translations {
+		lines[t.Key] = t.Lines
+	}
+
+	var issues []checkIssue
+	for _, m := range findMissing(translations) {
+		issues = append(issues, checkIssue{
+			Kind:    issueMissing,
+			Key:     m.Key,
+			Lang:    m.Lang,
+			File:    config.GetPropertiesFilePath(m.Lang),
+			Message: fmt.Sprintf("Missing translation for key '%s' in language '%s'", m.Key, m.Lang),
+		})
+	}
+	for _, m := range findPlaceholderMismatches(translations) {
+		issues = append(issues, checkIssue{
+			Kind:    issuePlaceholder,
+			Key:     m.Key,
+			Lang:    m.Lang,
+			File:    config.GetPropertiesFilePath(m.Lang),
+			Line:    lines[m.Key][m.Lang],
+			Message: fmt.Sprintf("Placeholder mismatch for key '%s' in language '%s': %s", m.Key, m.Lang, m),
+		})
+	}
+
+	duplicates, err := findDuplicates()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range duplicates {
+		others := make([]string, len(d.Lines)-1)
+		for i, line := range d.Lines[:len(d.Lines)-1] {
+			others[i] = fmt.Sprint(line)
+		}
+		issues = append(issues, checkIssue{
+			Kind:    issueDuplicate,
+			Key:     d.Key,
+			Lang:    d.Lang,
+			File:    config.GetPropertiesFilePath(d.Lang),
+			Line:    d.Lines[len(d.Lines)-1],
+			Message: fmt.Sprintf("Duplicate key '%s' in language '%s', also defined on line %s", d.Key, d.Lang, strings.Join(others, ", ")),
+		})
+	}
+
+	for i := range issues {
+		issues[i].Failing = failOn[issues[i].Kind]
+	}
+	return issues, nil
+}
+
+func HandleCheck(c *cli.Context) error {
+	failOn, err := parseFailOn(c.StringSlice("fail-on"))
+	if err != nil {
+		return err
+	}
+	issues, err := collectIssues(failOn)
+	if err != nil {
+		return err
+	}
+
+	switch format := c.String("format"); format {
+	case "", "text":
+		printIssuesText(issues)
+	case "json":
+		err = printIssuesJSON(issues)
+	case "junit":
+		err = printIssuesJUnit(issues)
+	case "sarif":
+		err = printIssuesSARIF(issues)
+	case "github":
+		printIssuesGitHub(issues)
+	default:
+		return fmt.Errorf("invalid --format '%s' (valid: text, json, junit, sarif, github)", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	failing := 0
+	for _, issue := range issues {
+		if issue.Failing {
+			failing++
+		}
+	}
+	if failing > 0 {
+		return fmt.Errorf("check failed: %d issue(s) found", failing)
+	}
+	return nil
+}
+
+// countIssues returns the number of issues of each kind.
+func countIssues(issues []checkIssue) map[string]int {
+	counts := make(map[string]int)
+	for _, issue := range issues {
+		counts[issue.Kind]++
+	}
+	return counts
+}
+
+func printIssuesText(issues []checkIssue) {
+	for _, issue := range issues {
+		if issue.Line > 0 {
+			fmt.Printf("%s (%s:%d)\n", issue.Message, issue.File, issue.Line)
+		} else {
+			fmt.Println(issue.Message)
+		}
+	}
+
+	if len(issues) == 0 {
+		fmt.Println("All translations are complete!")
+		return
+	}
+	counts := countIssues(issues)
+	if n := counts[issueMissing]; n > 0 {
+		fmt.Printf("Found %d missing translations\n", n)
+	}
+	if n := counts[issuePlaceholder]; n > 0 {
+		fmt.Printf("Found %d placeholder mismatches\n", n)
+	}
+	if n := counts[issueDuplicate]; n > 0 {
+		fmt.Printf("Found %d duplicate keys\n", n)
+	}
+}
+
+func printIssuesJSON(issues []checkIssue) error {
+	failed := false
+	for _, issue := range issues {
+		failed = failed || issue.Failing
+	}
+	counts := countIssues(issues)
+	summary := make(map[string]int, len(issueKinds))
+	for _, kind := range issueKinds {
+		summary[kind] = counts[kind]
+	}
+	report := struct {
+		Issues  []checkIssue   `json:"issues"`
+		Summary map[string]int `json:"summary"`
+		Failed  bool           `json:"failed"`
+	}{Issues: append([]checkIssue{}, issues...), Summary: summary, Failed: failed}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error formatting report: %v", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+// printIssuesJUnit writes one test suite per kind of check. Failing issues
+// become failed test cases; other issues pass with the message as output,
+// and a check without issues is a single passing test case.
+func printIssuesJUnit(issues []checkIssue) error {
+	report := junitTestSuites{Name: "i18n-manager check"}
+	for _, kind := range issueKinds {
+		suite := junitTestSuite{Name: kind}
+		for _, issue := range issues {
+			if issue.Kind != kind {
+				continue
+			}
+			tc := junitTestCase{Name: fmt.Sprintf("%s [%s]", issue.Key, issue.Lang), ClassName: issue.File}
+			if issue.Failing {
+				tc.Failure = &junitFailure{Message: issue.Message, Type: kind}
+				suite.Failures++
+			} else {
+				tc.SystemOut = issue.Message
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		if len(suite.Cases) == 0 {
+			suite.Cases = append(suite.Cases, junitTestCase{Name: issueDescriptions[kind], ClassName: kind})
+		}
+		suite.Tests = len(suite.Cases)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Suites = append(report.Suites, suite)
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error formatting report: %v", err)
+	}
+	fmt.Println(xml.Header + string(data))
+	return nil
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region *sarifRegion `json:"region,omitempty"`
+	} `json:"physicalLocation"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifRun struct {
+	Tool struct {
+		Driver struct {
+			Name           string      `json:"name"`
+			InformationURI string      `json:"informationUri"`
+			Rules          []sarifRule `json:"rules"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// printIssuesSARIF writes a SARIF 2.1.0 log, as consumed by GitHub code
+// scanning and GitLab.
+func printIssuesSARIF(issues []checkIssue) error {
+	var run sarifRun
+	run.Tool.Driver.Name = "i18n-manager"
+	run.Tool.Driver.InformationURI = "https://github.com/SimonGino/i18n-manager"
+	for _, kind := range issueKinds {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: kind, ShortDescription: sarifMessage{issueDescriptions[kind]}})
+	}
+
+	run.Results = []sarifResult{}
+	for _, issue := range issues {
+		var loc sarifLocation
+		loc.PhysicalLocation.ArtifactLocation.URI = issue.File
+		if issue.Line > 0 {
+			loc.PhysicalLocation.Region = &sarifRegion{StartLine: issue.Line}
+		}
+		level := "warning"
+		if issue.Failing {
+			level = "error"
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    issue.Kind,
+			Level:     level,
+			Message:   sarifMessage{issue.Message},
+			Locations: []sarifLocation{loc},
+		})
+	}
+
+	report := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error formatting report: %v", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// printIssuesGitHub writes GitHub Actions workflow commands, which show up as
+// annotations on the changed files of a pull request.
+func printIssuesGitHub(issues []checkIssue) {
+	escape := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	escapeProperty := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+	for _, issue := range issues {
+		command := "warning"
+		if issue.Failing {
+			command = "error"
+		}
+		properties := "file=" + escapeProperty.Replace(issue.File)
+		if issue.Line > 0 {
+			properties += fmt.Sprintf(",line=%d", issue.Line)
+		}
+		properties += ",title=" + escapeProperty.Replace("i18n "+issue.Kind)
+		fmt.Printf("::%s %s::%s\n", command, properties, escape.Replace(issue.Message))
+	}
+}
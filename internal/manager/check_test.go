@@ -0,0 +1,139 @@
+package manager
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// captureStdout returns what f prints to standard output.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- string(data)
+	}()
+	defer func() { os.Stdout = stdout }()
+	f()
+	w.Close()
+	return <-output
+}
+
+// checkGolden compares got with testdata/name, or rewrites the file with -update.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from the output\n got:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// sampleIssues has one issue of every kind. Only the missing translation is
+// failing, and the duplicate has characters GitHub commands must escape.
+var sampleIssues = []checkIssue{
+	{
+		Kind:    issueMissing,
+		Key:     "user.delete",
+		Lang:    "zh_TW",
+		File:    "i18n/messages_zh_TW.properties",
+		Message: "Missing translation for key 'user.delete' in language 'zh_TW'",
+		Failing: true,
+	},
+	{
+		Kind:    issuePlaceholder,
+		Key:     "user.missing",
+		Lang:    "en",
+		File:    "i18n/messages.properties",
+		Line:    3,
+		Message: "Placeholder mismatch for key 'user.missing' in language 'en': missing {0}",
+	},
+	{
+		Kind:    issueDuplicate,
+		Key:     "button.save",
+		Lang:    "zh",
+		File:    "i18n/a,b:messages_zh.properties",
+		Line:    7,
+		Message: "Duplicate key 'button.save' in language 'zh', also defined on line 1, 4\n100%",
+	},
+}
+
+func TestCheckFormats(t *testing.T) {
+	tests := []struct {
+		golden string
+		print  func([]checkIssue) error
+	}{
+		{"check.txt", func(issues []checkIssue) error { printIssuesText(issues); return nil }},
+		{"check.json", printIssuesJSON},
+		{"check.junit.xml", printIssuesJUnit},
+		{"check.sarif", printIssuesSARIF},
+		{"check.github.txt", func(issues []checkIssue) error { printIssuesGitHub(issues); return nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var err error
+			got := captureStdout(t, func() { err = tt.print(sampleIssues) })
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, tt.golden, got)
+		})
+	}
+}
+
+func TestCheckFailOn(t *testing.T) {
+	// user.delete 缺少英文，user.missing 的英文缺少占位符
+	setupProject(t, map[string]string{
+		"messages.properties":       "user.missing=User not found\n",
+		"messages_zh.properties":    "user.delete=\\u5220\\u9664{0}\nuser.missing=\\u7528\\u6237{0}\\u4e0d\\u5b58\\u5728\n",
+		"messages_zh_TW.properties": "user.delete=\\u522a\\u9664{0}\nuser.missing=\\u7528\\u6236{0}\\u4e0d\\u5b58\\u5728\n",
+	})
+
+	tests := []struct {
+		failOn  []string
+		wantErr string
+	}{
+		{failOn: nil, wantErr: "check failed: 2 issue(s) found"},
+		{failOn: []string{"missing"}, wantErr: "check failed: 1 issue(s) found"},
+		{failOn: []string{"placeholder,missing"}, wantErr: "check failed: 2 issue(s) found"},
+		{failOn: []string{"duplicate"}, wantErr: ""},
+		{failOn: []string{"typo"}, wantErr: "invalid --fail-on value 'typo'"},
+	}
+	for _, tt := range tests {
+		args := []string{"--format", "json"}
+		for _, f := range tt.failOn {
+			args = append(args, "--fail-on", f)
+		}
+		var err error
+		output := captureStdout(t, func() { err = runCommand(HandleCheck, args...) })
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("--fail-on %v: unexpected error: %v", tt.failOn, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("--fail-on %v: err = %v, want %q", tt.failOn, err, tt.wantErr)
+		case tt.wantErr == "" && !strings.Contains(output, `"failed": false`):
+			t.Errorf("--fail-on %v: report is failed:\n%s", tt.failOn, output)
+		}
+	}
+}
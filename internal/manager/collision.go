@@ -0,0 +1,162 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+// Ways to resolve a key that is already in use, as accepted by --on-conflict.
+const (
+	conflictReuse     = "reuse"
+	conflictSuffix    = "suffix"
+	conflictOverwrite = "overwrite"
+	conflictAbort     = "abort"
+)
+
+// resolveKeyConflict checks whether key is already defined before new
+// translations are saved under it. If it is, the existing and new values are
+// shown side by side and the conflict is resolved with action, the value of
+// --on-conflict, or by asking the user if action is empty. It returns the key
+// and the translations to save; a nil map means nothing should be saved.
+func resolveKeyConflict(c *cli.Context, key string, explicit bool, sourceLang string, translations map[string]string, action string) (string, map[string]string, error) {
+	all, err := loadAllTranslations()
+	if err != nil {
+		return "", nil, fmt.Errorf("error loading translations: %v", err)
+	}
+	var existing *Translation
+	taken := make(map[string]bool, len(all))
+	for i := range all {
+		taken[all[i].Key] = true
+		if all[i].Key == key {
+			existing = &all[i]
+		}
+	}
+	if existing == nil {
+		return key, translations, nil
+	}
+
+	sameSource := existing.Values[sourceLang] == translations[sourceLang]
+	suffixed := uniqueKey(key, taken)
+	fmt.Printf("\nKey '%s' already exists:\n", key)
+	printConflict(existing, translations)
+
+	switch action {
+	case conflictReuse, conflictSuffix, conflictOverwrite, conflictAbort:
+	case "":
+		switch {
+		case !c.Bool("yes"):
+			action = askConflictAction(sameSource, suffixed)
+			if action == conflictAbort {
+				return "", nil, nil
+			}
+		case sameSource:
+			action = conflictReuse
+		case explicit:
+			// 显式指定的键不自动改名，以免与调用方预期的键不一致
+			action = conflictAbort
+		default:
+			action = conflictSuffix
+		}
+	default:
+		return "", nil, fmt.Errorf("invalid --on-conflict '%s' (valid: reuse, suffix, overwrite, abort)", action)
+	}
+
+	switch action {
+	case conflictReuse:
+		if !sameSource {
+			return "", nil, fmt.Errorf("cannot reuse key '%s': its %s value is different", key, sourceLang)
+		}
+		// 只补充已有键缺少的语言
+		missing := make(map[string]string)
+		for _, mapping := range config.GetConfig().Language.Mappings {
+			value, ok := translations[mapping.Code]
+			if _, exists := existing.Values[mapping.Code]; ok && !exists {
+				missing[mapping.Code] = value
+			}
+		}
+		return key, missing, nil
+	case conflictSuffix:
+		fmt.Printf("Using key '%s' instead\n", suffixed)
+		return suffixed, translations, nil
+	case conflictOverwrite:
+		return key, translations, nil
+	}
+	return "", nil, fmt.Errorf("key '%s' already exists (use --on-conflict to resolve)", key)
+}
+
+// askConflictAction asks how to resolve a key conflict. Reusing the key is
+// only offered when the source text is the same.
+func askConflictAction(sameSource bool, suffixed string) string {
+	options := []string{fmt.Sprintf("[s] use '%s'", suffixed), "[o] overwrite", "[a] abort"}
+	actions := map[string]string{"s": conflictSuffix, "o": conflictOverwrite, "a": conflictAbort}
+	if sameSource {
+		options = append([]string{"[r] reuse the existing key"}, options...)
+		actions["r"] = conflictReuse
+	}
+
+	fmt.Printf("\n%s? ", strings.Join(options, ", "))
+	var response string
+	if _, err := fmt.Scanln(&response); err != nil {
+		// 直接按回车视为放弃
+		return conflictAbort
+	}
+	if action, ok := actions[strings.ToLower(response)]; ok {
+		return action
+	}
+	return conflictAbort
+}
+
+// printConflict prints the existing and new values of every language in two
+// columns.
+func printConflict(existing *Translation, translations map[string]string) {
+	rows := [][3]string{{"Language", "Existing", "New"}}
+	for _, mapping := range config.GetConfig().Language.Mappings {
+		old, hasOld := existing.Values[mapping.Code]
+		value, hasNew := translations[mapping.Code]
+		if !hasOld && !hasNew {
+			continue
+		}
+		if !hasOld {
+			old = "-"
+		}
+		if !hasNew {
+			value = "-"
+		}
+		rows = append(rows, [3]string{mapping.Code, old, value})
+	}
+
+	widths := [2]int{}
+	for _, row := range rows {
+		for i := range widths {
+			if w := displayWidth(row[i]); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	for _, row := range rows {
+		fmt.Printf("  %s  %s  %s\n", padRight(row[0], widths[0]), padRight(row[1], widths[1]), row[2])
+	}
+}
+
+// displayWidth returns the number of terminal columns of s, counting East
+// Asian wide characters as two.
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		if unicode.In(r, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana) ||
+			(r >= 0x3000 && r <= 0x303F) || (r >= 0xFF01 && r <= 0xFF60) {
+			width += 2
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", width-displayWidth(s))
+}
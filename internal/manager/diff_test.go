@@ -0,0 +1,81 @@
+package manager
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{
+			name:   "equal",
+			before: "a\nb\n",
+			after:  "a\nb\n",
+			want:   "",
+		},
+		{
+			name:   "insertion",
+			before: "a\nb\nc\n",
+			after:  "a\nb\nx\nc\n",
+			want:   "--- a/f\n+++ b/f\n@@ -1,3 +1,4 @@\n a\n b\n+x\n c\n",
+		},
+		{
+			name:   "deletion",
+			before: "a\nb\nc\n",
+			after:  "a\nc\n",
+			want:   "--- a/f\n+++ b/f\n@@ -1,3 +1,2 @@\n a\n-b\n c\n",
+		},
+		{
+			name:   "change at start",
+			before: "a\n2\n3\n4\n5\n6\n",
+			after:  "x\n2\n3\n4\n5\n6\n",
+			want:   "--- a/f\n+++ b/f\n@@ -1,4 +1,4 @@\n-a\n+x\n 2\n 3\n 4\n",
+		},
+		{
+			name:   "change at end",
+			before: "1\n2\n3\n4\n5\nz\n",
+			after:  "1\n2\n3\n4\n5\nx\n",
+			want:   "--- a/f\n+++ b/f\n@@ -3,4 +3,4 @@\n 3\n 4\n 5\n-z\n+x\n",
+		},
+		{
+			name:   "separate hunks",
+			before: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			after:  "0\n2\n3\n4\n5\n6\n7\n8\n9\nx\n",
+			want: "--- a/f\n+++ b/f\n@@ -1,4 +1,4 @@\n-1\n+0\n 2\n 3\n 4\n" +
+				"@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+x\n",
+		},
+		{
+			name:   "new file",
+			before: "",
+			after:  "a\nb\n",
+			want:   "--- a/f\n+++ b/f\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:   "emptied file",
+			before: "a\n",
+			after:  "",
+			want:   "--- a/f\n+++ b/f\n@@ -1,1 +0,0 @@\n-a\n",
+		},
+		{
+			name:   "no trailing newline",
+			before: "a\nb",
+			after:  "a\nb\nc",
+			want:   "--- a/f\n+++ b/f\n@@ -1,2 +1,3 @@\n a\n-b\n\\ No newline at end of file\n+b\n+c\n\\ No newline at end of file\n",
+		},
+		{
+			name:   "CRLF line endings",
+			before: "a\r\nb\r\n",
+			after:  "a\r\nc\r\n",
+			want:   "--- a/f\n+++ b/f\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("f", []byte(tt.before), []byte(tt.after)); got != tt.want {
+				t.Errorf("\n got: %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package manager
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/SimonGino/i18n-manager/internal/tm"
+	"github.com/urfave/cli/v2"
+)
+
+const defaultDuplicateThreshold = 0.9
+
+// halfWidth maps full-width punctuation to the half-width form, so that
+// texts differing only in punctuation width compare equal.
+var halfWidth = map[rune]rune{'，': ',', '；': ';', '：': ':', '！': '!', '？': '?', '。': '.', '（': '(', '）': ')'}
+
+// normalizeSource prepares a source text for comparison: case, whitespace,
+// punctuation width and trailing punctuation are ignored.
+func normalizeSource(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if half, ok := halfWidth[r]; ok {
+			return half
+		}
+		return r
+	}, strings.ToLower(s))
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.TrimRightFunc(s, func(r rune) bool { return unicode.IsPunct(r) || unicode.IsSpace(r) })
+}
+
+func duplicateThreshold() float64 {
+	if t := config.GetConfig().DuplicateThreshold; t > 0 {
+		return t
+	}
+	return defaultDuplicateThreshold
+}
+
+// sourceMatch is an existing key whose source-language value is the same as
+// or similar to a new text.
+type sourceMatch struct {
+	Translation
+	Value string
+	Score float64
+}
+
+// findSourceMatches returns the keys whose value in lang is identical or
+// near-identical to text, best match first.
+func findSourceMatches(translations []Translation, lang, text string, threshold float64) []sourceMatch {
+	normalized := normalizeSource(text)
+	var matches []sourceMatch
+	for _, t := range translations {
+		value, ok := t.Values[lang]
+		if !ok {
+			continue
+		}
+		score := 1.0
+		if n := normalizeSource(value); n != normalized {
+			if score = tm.Similarity(n, normalized); score < threshold {
+				continue
+			}
+		}
+		matches = append(matches, sourceMatch{Translation: t, Value: value, Score: score})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		// 完全相同的原文排在最前
+		if (matches[i].Value == text) != (matches[j].Value == text) {
+			return matches[i].Value == text
+		}
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+// suggestExistingKey looks for keys whose source-language value is the same
+// as or similar to text before it is translated, and offers to use one of
+// them instead of adding a new entry. It returns the chosen match, or nil to
+// add a new translation. With --yes an identical text reuses the existing key
+// unless a key was given with --key.
+func suggestExistingKey(c *cli.Context, text, key, sourceLang string) (*sourceMatch, error) {
+	translations, err := loadAllTranslations()
+	if err != nil {
+		return nil, fmt.Errorf("error loading translations: %v", err)
+	}
+	var matches []sourceMatch
+	for _, m := range findSourceMatches(translations, sourceLang, text, duplicateThreshold()) {
+		// 与指定的键相同的情况由键冲突处理
+		if m.Key != key {
+			matches = append(matches, m)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	fmt.Println("\nThis text is already translated under other keys:")
+	for i, m := range matches {
+		fmt.Printf("  [%d] %s: %s (%.0f%%)\n", i+1, m.Key, m.Value, m.Score*100)
+	}
+
+	chosen := -1
+	if c.Bool("yes") {
+		if key == "" && matches[0].Value == text {
+			chosen = 0
+		} else {
+			fmt.Println("Adding a new translation anyway")
+		}
+	} else {
+		fmt.Print("\nEnter a number to use that key, or press Enter to add a new translation: ")
+		var response string
+		if _, err := fmt.Scanln(&response); err == nil {
+			if n, err := strconv.Atoi(response); err == nil && n >= 1 && n <= len(matches) {
+				chosen = n - 1
+			}
+		}
+	}
+	if chosen < 0 {
+		return nil, nil
+	}
+
+	m := matches[chosen]
+	fmt.Printf("\nUsing existing key: %s\n", m.Key)
+	for _, mapping := range config.GetConfig().Language.Mappings {
+		if value, ok := m.Values[mapping.Code]; ok {
+			fmt.Printf("%s: %s\n", mapping.Code, value)
+		}
+	}
+	return &m, nil
+}
+
+// duplicateGroup is a set of keys with the same or similar values.
+type duplicateGroup struct {
+	Value string
+	Keys  []Translation
+}
+
+// findDuplicateGroups groups the keys whose values in lang are identical
+// after normalization. With near, groups whose values are similar enough are
+// merged as well.
+func findDuplicateGroups(translations []Translation, lang string, near bool) []duplicateGroup {
+	var groups []*duplicateGroup
+	byValue := make(map[string]*duplicateGroup)
+	var normalized []string
+	for _, t := range translations {
+		value, ok := t.Values[lang]
+		n := normalizeSource(value)
+		if !ok || n == "" {
+			continue
+		}
+		g, ok := byValue[n]
+		if !ok {
+			g = &duplicateGroup{Value: value}
+			byValue[n] = g
+			groups = append(groups, g)
+			normalized = append(normalized, n)
+		}
+		g.Keys = append(g.Keys, t)
+	}
+
+	if near {
+		// 相似度不具有传递性，这里把相似的组串联合并
+		threshold := duplicateThreshold()
+		parent := make([]int, len(groups))
+		for i := range parent {
+			parent[i] = i
+		}
+		var find func(i int) int
+		find = func(i int) int {
+			if parent[i] != i {
+				parent[i] = find(parent[i])
+			}
+			return parent[i]
+		}
+		for i := range groups {
+			for j := i + 1; j < len(groups); j++ {
+				if tm.Similarity(normalized[i], normalized[j]) >= threshold {
+					parent[find(j)] = find(i)
+				}
+			}
+		}
+		merged := make([]*duplicateGroup, 0, len(groups))
+		for i, g := range groups {
+			if root := find(i); root != i {
+				groups[root].Keys = append(groups[root].Keys, g.Keys...)
+			} else {
+				merged = append(merged, g)
+			}
+		}
+		groups = merged
+	}
+
+	var result []duplicateGroup
+	for _, g := range groups {
+		if len(g.Keys) > 1 {
+			sort.Slice(g.Keys, func(i, j int) bool { return g.Keys[i].Key < g.Keys[j].Key })
+			result = append(result, *g)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Keys[0].Key < result[j].Keys[0].Key })
+	return result
+}
+
+func HandleDupes(c *cli.Context) error {
+	lang := c.String("lang")
+	if lang == "" {
+		sourceLang := config.GetSourceLang()
+		if sourceLang == nil {
+			return fmt.Errorf("no source language configured")
+		}
+		lang = sourceLang.Code
+	}
+	translations, err := loadAllTranslations()
+	if err != nil {
+		return fmt.Errorf("error loading translations: %v", err)
+	}
+
+	groups := findDuplicateGroups(translations, lang, c.Bool("near"))
+	if len(groups) == 0 {
+		fmt.Println("No keys share a value")
+		return nil
+	}
+
+	filename := config.GetPropertiesFilePath(lang)
+	keys := 0
+	for _, g := range groups {
+		fmt.Printf("%s (%d keys)\n", g.Value, len(g.Keys))
+		for _, t := range g.Keys {
+			fmt.Printf("  %s: %s (%s:%d)\n", t.Key, t.Values[lang], filename, t.Lines[lang])
+		}
+		fmt.Println()
+		keys += len(g.Keys)
+	}
+	fmt.Printf("Found %d value(s) shared by %d key(s)\n", len(groups), keys)
+	return nil
+}
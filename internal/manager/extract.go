@@ -0,0 +1,589 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/SimonGino/i18n-manager/internal/ai"
+	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+// defaultExtractExpression replaces an extracted literal when extract_expression
+// is not configured. %s is the message key.
+const defaultExtractExpression = `messageSource.getMessage("%s", null, LocaleContextHolder.getLocale())`
+
+// javaLiteral is a string literal in a Java file. Start and End are the byte
+// offsets of the literal including its quotes.
+type javaLiteral struct {
+	File     string
+	Line     int
+	Start    int
+	End      int
+	Text     string
+	Constant string // 不能替换为消息查找的上下文，如 case 标签
+}
+
+// logCallPattern matches logging calls, whose messages are not shown to users.
+var logCallPattern = regexp.MustCompile(`(?i)\b(log|logger)\.(trace|debug|info|warn|error)\s*\(`)
+
+var (
+	// caseLabelPattern matches the start of a case label up to a literal:
+	// case "a": and case "a", "b" ->
+	caseLabelPattern = regexp.MustCompile(`\bcase\s[^:>]*$`)
+	// constantPattern matches a static final field declaration up to its
+	// initializer.
+	constantPattern = regexp.MustCompile(`\b(static\s+final|final\s+static)\b[^=]*=`)
+	// enumHeaderPattern matches an enum declaration up to its opening brace.
+	enumHeaderPattern = regexp.MustCompile(`\benum\s+\w+[^;{}]*$`)
+)
+
+// findJavaStringLiterals returns the string literals of a Java source file
+// that contain non-ASCII letters. Comments, character literals and text blocks
+// are skipped, and so are literals in annotations and logging calls, which
+// cannot or should not be replaced with a message lookup.
+func findJavaStringLiterals(path, content string) []javaLiteral {
+	var literals []javaLiteral
+	line := 1
+	for i := 0; i < len(content); i++ {
+		switch {
+		case content[i] == '\n':
+			line++
+		case strings.HasPrefix(content[i:], "//"):
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				return literals
+			}
+			i += end - 1
+		case strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				return literals
+			}
+			line += strings.Count(content[i:i+2+end], "\n")
+			i += end + 3
+		case strings.HasPrefix(content[i:], `"""`):
+			end := strings.Index(content[i+3:], `"""`)
+			if end < 0 {
+				return literals
+			}
+			line += strings.Count(content[i:i+3+end], "\n")
+			i += end + 5
+		case content[i] == '\'' || content[i] == '"':
+			quote := content[i]
+			j := i + 1
+			for j < len(content) && content[j] != quote && content[j] != '\n' {
+				if content[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(content) || content[j] != quote {
+				// 未闭合的字面量，跳过本行剩余部分
+				i = j - 1
+				continue
+			}
+			if quote == '"' {
+				text := unquoteJava(content[i+1 : j])
+				if hasNonASCIILetter(text) && !skipLiteral(content, i) {
+					literals = append(literals, javaLiteral{
+						File:     path,
+						Line:     line,
+						Start:    i,
+						End:      j + 1,
+						Text:     text,
+						Constant: constantContext(content, i),
+					})
+				}
+			}
+			i = j
+		}
+	}
+	return literals
+}
+
+// annotationPattern matches an annotation up to the parenthesis of its
+// arguments.
+var annotationPattern = regexp.MustCompile(`@[\w.]+\s*\(`)
+
+// skipLiteral reports whether the literal starting at offset is an argument of
+// an annotation or a logging call. The arguments may continue on later lines,
+// so the whole statement before the literal is checked.
+func skipLiteral(content string, offset int) bool {
+	statement := content[statementStart(content, offset):offset]
+	open := unclosedParens(statement)
+	for _, pattern := range []*regexp.Regexp{annotationPattern, logCallPattern} {
+		for _, m := range pattern.FindAllStringIndex(statement, -1) {
+			if open[m[1]-1] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// statementStart returns the offset just after the last statement or block
+// boundary before offset. The braces of array initializers are not
+// boundaries, so a literal in {"a", "b"} belongs to the enclosing statement.
+func statementStart(content string, offset int) int {
+	end := offset
+	for {
+		boundary := strings.LastIndexAny(content[:end], ";{}")
+		if boundary < 0 {
+			return 0
+		}
+		if content[boundary] == '{' {
+			before := strings.TrimRightFunc(content[:boundary], unicode.IsSpace)
+			if before != "" && strings.ContainsRune("=(,]", rune(before[len(before)-1])) {
+				end = boundary
+				continue
+			}
+		}
+		return boundary + 1
+	}
+}
+
+// unclosedParens returns the offsets of the opening parentheses in s that are
+// not closed within s. Parentheses in string and character literals are
+// ignored.
+func unclosedParens(s string) map[int]bool {
+	var stack []int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			stack = append(stack, i)
+		case ')':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case '"', '\'':
+			quote := s[i]
+			for i++; i < len(s) && s[i] != quote; i++ {
+				if s[i] == '\\' {
+					i++
+				}
+			}
+		}
+	}
+	open := make(map[int]bool, len(stack))
+	for _, i := range stack {
+		open[i] = true
+	}
+	return open
+}
+
+// constantContext describes where the literal starting at offset cannot be
+// replaced with a message lookup: in a case label, which must be a
+// compile-time constant, or in a static final initializer or enum constant
+// argument, which run without an instance to look messages up with. It
+// returns "" for any other literal.
+func constantContext(content string, offset int) string {
+	// 从上一个语句或代码块的边界开始判断，初始化表达式可能跨行
+	start := statementStart(content, offset)
+	statement := content[start:offset]
+	switch {
+	case caseLabelPattern.MatchString(statement):
+		return "in a case label"
+	case constantPattern.MatchString(statement):
+		return "in a static final initializer"
+	case start > 0 && content[start-1] == '{':
+		header := content[:start-1]
+		header = header[strings.LastIndexAny(header, ";{}")+1:]
+		// 枚举常量位于枚举体中第一个分号之前
+		if enumHeaderPattern.MatchString(header) {
+			return "in an enum constant argument"
+		}
+	}
+	return ""
+}
+
+func hasNonASCIILetter(s string) bool {
+	for _, r := range s {
+		if r >= utf8.RuneSelf && unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// unquoteJava decodes the escape sequences of a Java string literal body.
+func unquoteJava(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 's':
+			b.WriteByte(' ')
+		case 'u':
+			// \uXXXX，允许多个 u
+			j := i
+			for j < len(s) && s[j] == 'u' {
+				j++
+			}
+			if j+4 <= len(s) {
+				if code, err := strconv.ParseUint(s[j:j+4], 16, 32); err == nil {
+					b.WriteRune(rune(code))
+					i = j + 3
+					continue
+				}
+			}
+			b.WriteString(`\u`)
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// javaEscape escapes s for use in the body of a Java string literal.
+func javaEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\', '"':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < ' ' {
+				// Java 在词法分析前处理 \u 转义，控制字符只能用八进制转义
+				fmt.Fprintf(&b, `\%03o`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// extractedMessage is a distinct text found in the sources and the key it is
+// stored under.
+type extractedMessage struct {
+	Text     string
+	Key      string
+	Existing bool // 源语言文件中已有相同的值，复用已有的键
+	Literals []javaLiteral
+}
+
+func extractExpression() string {
+	if expr := config.GetConfig().ExtractExpression; expr != "" {
+		return expr
+	}
+	return defaultExtractExpression
+}
+
+func HandleExtract(c *cli.Context) error {
+	sourceLang := config.GetSourceLang()
+	if sourceLang == nil {
+		return fmt.Errorf("no source language configured")
+	}
+	expr := extractExpression()
+	if c.Bool("rewrite") && strings.Count(expr, "%s") != 1 {
+		return fmt.Errorf("extract_expression must contain exactly one %%s for the key: %s", expr)
+	}
+
+	var literals []javaLiteral
+	err := walkSources(c.String("src"), func(path string) error {
+		if !strings.EqualFold(filepath.Ext(path), ".java") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", path, err)
+		}
+		literals = append(literals, findJavaStringLiterals(path, string(data))...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(literals) == 0 {
+		fmt.Println("No hard-coded non-ASCII strings found")
+		return nil
+	}
+
+	// case 标签、常量等位置的字面量不能替换为方法调用，只列出来由用户手动处理
+	var constants []javaLiteral
+	extractable := literals[:0]
+	for _, lit := range literals {
+		if lit.Constant != "" {
+			constants = append(constants, lit)
+		} else {
+			extractable = append(extractable, lit)
+		}
+	}
+	literals = extractable
+	for _, lit := range constants {
+		fmt.Printf("Skipping %q at %s:%d: cannot be replaced with a message lookup %s\n", lit.Text, filepath.ToSlash(lit.File), lit.Line, lit.Constant)
+	}
+	if len(literals) == 0 {
+		fmt.Println("No extractable strings found")
+		return nil
+	}
+
+	// 相同的文本只提取一次
+	var messages []*extractedMessage
+	byText := make(map[string]*extractedMessage)
+	for _, lit := range literals {
+		m, ok := byText[lit.Text]
+		if !ok {
+			m = &extractedMessage{Text: lit.Text}
+			byText[lit.Text] = m
+			messages = append(messages, m)
+		}
+		m.Literals = append(m.Literals, lit)
+	}
+
+	if c.Bool("dry-run") {
+		for _, m := range messages {
+			fmt.Printf("%q\n", m.Text)
+			for _, lit := range m.Literals {
+				fmt.Printf("  %s:%d\n", filepath.ToSlash(lit.File), lit.Line)
+			}
+		}
+		fmt.Printf("\nDry run, found %d string(s) in %d literal(s)\n", len(messages), len(literals))
+		return nil
+	}
+
+	translations, err := loadAllTranslations()
+	if err != nil {
+		return fmt.Errorf("error loading translations: %v", err)
+	}
+	existingKeys := make(map[string]bool, len(translations))
+	for _, t := range translations {
+		existingKeys[t.Key] = true
+		if value, ok := t.Values[sourceLang.Code]; ok {
+			if m, ok := byText[value]; ok && !m.Existing {
+				m.Key, m.Existing = t.Key, true
+			}
+		}
+	}
+
+	keys, err := newKeyGenerator(c, c.String("src"))
+	if err != nil {
+		return err
+	}
+	values, failed, err := translateExtracted(messages, sourceLang.Code, keys.needsEnglish())
+	if err != nil {
+		return err
+	}
+
+	// 为新的文本生成键，ai 方式需要逐条请求，因此并发生成
+	generated := make([]string, len(messages))
+	keyErrs := make([]error, len(messages))
+	ai.ForEach(len(messages), func(i int) {
+		m := messages[i]
+		if m.Existing {
+			return
+		}
+		english, ok := values["en"][strconv.Itoa(i)]
+		if sourceLang.Code == "en" {
+			english, ok = m.Text, true
+		}
+		if !ok && keys.needsEnglish() {
+			return
+		}
+		generated[i], keyErrs[i] = keys.generate(keyText{
+			Source:     m.Text,
+			SourceLang: sourceLang.Code,
+			English:    english,
+			File:       m.Literals[0].File,
+		})
+	})
+
+	// 保证生成的键与已有的键不冲突
+	tx := newTransaction()
+	var added []*extractedMessage
+	for i, m := range messages {
+		id := strconv.Itoa(i)
+		if !m.Existing {
+			if keyErrs[i] != nil {
+				failed = append(failed, fmt.Sprintf("%q (key: %v)", m.Text, keyErrs[i]))
+				continue
+			}
+			if generated[i] == "" {
+				continue
+			}
+			m.Key = uniqueKey(generated[i], existingKeys)
+			existingKeys[m.Key] = true
+
+			for _, mapping := range config.GetConfig().Language.Mappings {
+				value := m.Text
+				if !mapping.IsSource {
+					var ok bool
+					if value, ok = values[mapping.Code][id]; !ok {
+						continue
+					}
+				}
+				doc, err := tx.document(config.GetPropertiesFilePath(mapping.Code))
+				if err != nil {
+					return err
+				}
+				doc.Set(m.Key, prepareValue(mapping.Code, m.Key, value))
+			}
+		}
+		added = append(added, m)
+	}
+
+	if c.Bool("rewrite") {
+		if err := rewriteLiterals(tx, added, expr); err != nil {
+			return err
+		}
+	}
+
+	changed := tx.changed()
+	for _, filename := range changed {
+		f := tx.files[filename]
+		fmt.Print(unifiedDiff(filepath.ToSlash(filename), f.original, f.bytes()))
+	}
+	fmt.Println()
+	for _, m := range added {
+		if m.Existing {
+			fmt.Printf("%s: %q (existing key)\n", m.Key, m.Text)
+		} else {
+			fmt.Printf("%s: %q\n", m.Key, m.Text)
+		}
+	}
+	for _, f := range failed {
+		fmt.Printf("Failed: %s\n", f)
+	}
+
+	if len(changed) == 0 {
+		fmt.Println("Nothing to change")
+		return nil
+	}
+	if !c.Bool("yes") && !confirm(fmt.Sprintf("\nApply these changes to %d file(s)? (y/N): ", len(changed))) {
+		fmt.Println("Extraction cancelled")
+		return nil
+	}
+	if _, err := tx.commit(); err != nil {
+		return fmt.Errorf("error saving changes: %v", err)
+	}
+
+	fmt.Printf("Extracted %d string(s) into %d file(s)\n", len(added), len(changed))
+	if len(failed) > 0 {
+		return fmt.Errorf("%d translation(s) failed", len(failed))
+	}
+	return nil
+}
+
+// translateExtracted translates the new messages into every target language,
+// and into English if the keys are generated from it. Results are keyed by
+// language and the index of the message.
+func translateExtracted(messages []*extractedMessage, sourceLang string, english bool) (map[string]map[string]string, []string, error) {
+	langs := []string{}
+	needEnglish := english && sourceLang != "en"
+	for _, mapping := range config.GetTargetLangs() {
+		langs = append(langs, mapping.Code)
+		if mapping.Code == "en" {
+			needEnglish = false
+		}
+	}
+	if needEnglish {
+		langs = append(langs, "en")
+	}
+
+	var items []syncItem
+	for i, m := range messages {
+		if !m.Existing {
+			items = append(items, syncItem{missingTranslation: missingTranslation{Key: strconv.Itoa(i)}, Source: m.Text})
+		}
+	}
+	values := make(map[string]map[string]string, len(langs))
+	if len(items) == 0 {
+		return values, nil, nil
+	}
+
+	results := make([]map[string]string, len(langs))
+	errs := make([]error, len(langs))
+	ai.ForEach(len(langs), func(i int) {
+		results[i], errs[i] = translateSyncItems(items, sourceLang, langs[i])
+	})
+
+	var failed []string
+	for i, lang := range langs {
+		var batchErr *ai.BatchError
+		if errs[i] != nil && !errors.As(errs[i], &batchErr) {
+			return nil, nil, fmt.Errorf("error translating to %s: %v", lang, errs[i])
+		}
+		values[lang] = results[i]
+		for _, item := range items {
+			if _, ok := results[i][item.Key]; !ok {
+				index, _ := strconv.Atoi(item.Key)
+				failed = append(failed, fmt.Sprintf("%q (%s)", messages[index].Text, lang))
+			}
+		}
+	}
+	return values, failed, nil
+}
+
+// rewriteLiterals replaces the extracted literals with the message lookup
+// expression and stages the changed Java files.
+func rewriteLiterals(tx *transaction, messages []*extractedMessage, expr string) error {
+	byFile := make(map[string][]javaLiteral)
+	keys := make(map[string]string)
+	for _, m := range messages {
+		keys[m.Text] = m.Key
+		for _, lit := range m.Literals {
+			byFile[lit.File] = append(byFile[lit.File], lit)
+		}
+	}
+
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		original, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", file, err)
+		}
+		lits := byFile[file]
+		sort.Slice(lits, func(i, j int) bool { return lits[i].Start < lits[j].Start })
+
+		var b strings.Builder
+		last := 0
+		for _, lit := range lits {
+			b.Write(original[last:lit.Start])
+			// 表达式由用户配置，不能作为格式串使用
+			b.WriteString(strings.Replace(expr, "%s", javaEscape(keys[lit.Text]), 1))
+			last = lit.End
+		}
+		b.Write(original[last:])
+		tx.stage(file, original, []byte(b.String()))
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindJavaStringLiterals(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "non-ASCII literals",
+			input: "String a = \"保存\";\nString b = \"save\";\nString c = \"删除\";\n",
+			want:  []string{"保存", "删除"},
+		},
+		{
+			name:  "escapes",
+			input: "String a = \"\\u4fdd\\u5b58\";\nString b = \"说\\\"好\\\"\";\n",
+			want:  []string{"保存", "说\"好\""},
+		},
+		{
+			name:  "comments",
+			input: "// \"注释\"\n/* \"块注释\"\n\"续\" */\nString a = \"保存\";\n",
+			want:  []string{"保存"},
+		},
+		{
+			name:  "character literals and text blocks",
+			input: "char c = '中';\nchar q = '\"';\nString t = \"\"\"\n    文本块\n    \"\"\";\nString a = \"保存\";\n",
+			want:  []string{"保存"},
+		},
+		{
+			name:  "annotations and logging calls",
+			input: "@ApiOperation(\"查询\")\npublic void f() {\n    log.info(\"开始\");\n    logger.warn(\"用户{}\", id);\n    throw new RuntimeException(\"失败\");\n}\n",
+			want:  []string{"失败"},
+		},
+		{
+			name:  "multi-line annotations and logging calls",
+			input: "@ApiOperation(\n    value = \"查询\",\n    notes = {\"说明\"})\npublic void f() {\n    log.info(\n        \"开始{}\",\n        id);\n}\n",
+			want:  nil,
+		},
+		{
+			name:  "after a closed annotation or logging call",
+			input: "@Value(\"${a}\")\n@Autowired\nprivate String a = \"保存\";\nvoid f() {\n    log.info(\"x\"); String b = format(\"删除\");\n}\n",
+			want:  []string{"保存", "删除"},
+		},
+		{
+			name:  "parenthesis in an earlier literal",
+			input: "void f() {\n    log.info(\"(\" + \")\", \"开始\");\n    String a = g(\")\", \"保存\");\n}\n",
+			want:  []string{"保存"},
+		},
+		{
+			name:  "unterminated literal",
+			input: "String a = \"未闭合;\nString b = \"保存\";\n",
+			want:  []string{"保存"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, lit := range findJavaStringLiterals("A.java", tt.input) {
+				got = append(got, lit.Text)
+				if quoted := tt.input[lit.Start:lit.End]; quoted[0] != '"' || quoted[len(quoted)-1] != '"' {
+					t.Errorf("literal %q spans %q", lit.Text, quoted)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindJavaStringLiteralsLines(t *testing.T) {
+	input := "/* a\nb */ String a = \"一\";\nString t = \"\"\"\nx\n\"\"\"; String b = \"二\";\n"
+	var got []int
+	for _, lit := range findJavaStringLiterals("A.java", input) {
+		got = append(got, lit.Line)
+	}
+	if want := []int{2, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got lines %v, want %v", got, want)
+	}
+}
+
+func TestConstantContext(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "local variable",
+			input: "void f() {\n    String s = \"中文\";\n}\n",
+			want:  "",
+		},
+		{
+			name:  "instance field",
+			input: "class A {\n    private final String s = \"中文\";\n}\n",
+			want:  "",
+		},
+		{
+			name:  "case label",
+			input: "switch (s) {\ncase \"中文\":\n    break;\n}\n",
+			want:  "in a case label",
+		},
+		{
+			name:  "arrow case label",
+			input: "switch (s) {\ncase \"甲\", \"中文\" -> 1;\n}\n",
+			want:  "in a case label",
+		},
+		{
+			name:  "case body",
+			input: "switch (s) {\ncase \"a\":\n    return \"中文\";\n}\n",
+			want:  "",
+		},
+		{
+			name:  "static final field",
+			input: "class A {\n    static final String S = \"中文\";\n}\n",
+			want:  "in a static final initializer",
+		},
+		{
+			name:  "multi-line static final initializer",
+			input: "class A {\n    public final static String S =\n        \"前缀\" +\n        \"中文\";\n}\n",
+			want:  "in a static final initializer",
+		},
+		{
+			name:  "static final array",
+			input: "class A {\n    static final String[] S = {\"甲\", \"中文\"};\n}\n",
+			want:  "in a static final initializer",
+		},
+		{
+			name:  "enum constant",
+			input: "enum Status {\n    ACTIVE(\"启用\"),\n    INACTIVE(\"中文\");\n}\n",
+			want:  "in an enum constant argument",
+		},
+		{
+			name:  "instance array",
+			input: "void f() {\n    String[] s = new String[] {\"中文\"};\n}\n",
+			want:  "",
+		},
+		{
+			name:  "enum method",
+			input: "enum Status {\n    ACTIVE;\n    String label() {\n        return \"中文\";\n    }\n}\n",
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset := strings.Index(tt.input, `"中文"`)
+			if got := constantContext(tt.input, offset); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewriteLiterals(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "A.java")
+	input := "String a = \"保存\";\nString b = \"删除\" + \"保存\";\n"
+	if err := os.WriteFile(file, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	literals := findJavaStringLiterals(file, input)
+	messages := []*extractedMessage{
+		{Text: "保存", Key: "button.save", Literals: []javaLiteral{literals[0], literals[2]}},
+		{Text: "删除", Key: `odd"key\`, Literals: []javaLiteral{literals[1]}},
+	}
+	tests := []struct {
+		name string
+		expr string
+		want string
+	}{
+		{
+			name: "default expression",
+			expr: defaultExtractExpression,
+			want: "String a = messageSource.getMessage(\"button.save\", null, LocaleContextHolder.getLocale());\n" +
+				"String b = messageSource.getMessage(\"odd\\\"key\\\\\", null, LocaleContextHolder.getLocale()) + messageSource.getMessage(\"button.save\", null, LocaleContextHolder.getLocale());\n",
+		},
+		{
+			name: "expression with percent signs",
+			expr: `I18n.format("%d%%", "%s")`,
+			want: "String a = I18n.format(\"%d%%\", \"button.save\");\n" +
+				"String b = I18n.format(\"%d%%\", \"odd\\\"key\\\\\") + I18n.format(\"%d%%\", \"button.save\");\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := newTransaction()
+			if err := rewriteLiterals(tx, messages, tt.expr); err != nil {
+				t.Fatal(err)
+			}
+			if got := string(tx.files[file].bytes()); got != tt.want {
+				t.Errorf("\n got: %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJavaEscape(t *testing.T) {
+	tests := map[string]string{
+		"user.save": "user.save",
+		`a"b`:       `a\"b`,
+		`a\b`:       `a\\b`,
+		"a\nb":      `a\nb`,
+		"a\x01b":    `a\001b`,
+	}
+	for input, want := range tests {
+		if got := javaEscape(input); got != want {
+			t.Errorf("javaEscape(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/SimonGino/i18n-manager/internal/ai"
+	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/SimonGino/i18n-manager/internal/glossary"
+	"github.com/urfave/cli/v2"
+)
+
+// glossarySourceLang returns the --source-lang flag, defaulting to the
+// configured source language.
+func glossarySourceLang(c *cli.Context) (string, error) {
+	if lang := c.String("source-lang"); lang != "" {
+		return lang, nil
+	}
+	sourceLang := config.GetSourceLang()
+	if sourceLang == nil {
+		return "", fmt.Errorf("no source language configured")
+	}
+	return sourceLang.Code, nil
+}
+
+func formatTerm(t glossary.Term) string {
+	targetLang := t.TargetLang
+	if targetLang == "" {
+		targetLang = "*"
+	}
+	target := t.Target
+	if target == "" {
+		target = t.Source + " (keep)"
+	}
+	return fmt.Sprintf("[%s -> %s] %s => %s", t.SourceLang, targetLang, t.Source, target)
+}
+
+// printGlossaryWarnings reports the glossary terms a translation ignored and
+// returns how many there were.
+func printGlossaryWarnings(req ai.TranslationRequest, result, label string) int {
+	violations := ai.GlossaryViolations(req, result)
+	for _, t := range violations {
+		fmt.Printf("Warning: %s ignores glossary term %s\n", label, formatTerm(t))
+	}
+	return len(violations)
+}
+
+func HandleGlossaryAdd(c *cli.Context) error {
+	source := c.String("source")
+	if source == "" {
+		return fmt.Errorf("please specify --source")
+	}
+	target := c.String("target")
+	targetLang := c.String("target-lang")
+	if target != "" && targetLang == "" {
+		return fmt.Errorf("please specify --target-lang for the translation, or omit --target to keep the term unchanged in every language")
+	}
+	sourceLang, err := glossarySourceLang(c)
+	if err != nil {
+		return err
+	}
+
+	g, err := glossary.Load(glossary.Path())
+	if err != nil {
+		return err
+	}
+	term := glossary.Term{SourceLang: sourceLang, TargetLang: targetLang, Source: source, Target: target}
+	replaced := g.Add(term)
+	if err := g.Save(); err != nil {
+		return err
+	}
+
+	if replaced {
+		fmt.Printf("Updated glossary term %s\n", formatTerm(term))
+	} else {
+		fmt.Printf("Added glossary term %s\n", formatTerm(term))
+	}
+	return nil
+}
+
+func HandleGlossaryList(c *cli.Context) error {
+	g, err := glossary.Load(glossary.Path())
+	if err != nil {
+		return err
+	}
+
+	targetLang := c.String("target-lang")
+	count := 0
+	for _, t := range g.Terms {
+		if targetLang != "" && t.TargetLang != "" && t.TargetLang != targetLang {
+			continue
+		}
+		fmt.Println(formatTerm(t))
+		count++
+	}
+	if count == 0 {
+		fmt.Printf("No glossary terms in %s\n", glossary.Path())
+		return nil
+	}
+	fmt.Printf("\n%d term(s) in %s\n", count, glossary.Path())
+	return nil
+}
+
+func HandleGlossaryRemove(c *cli.Context) error {
+	source := c.String("source")
+	if source == "" {
+		return fmt.Errorf("please specify --source")
+	}
+
+	g, err := glossary.Load(glossary.Path())
+	if err != nil {
+		return err
+	}
+	removed := g.Remove(source, c.String("target-lang"))
+	if removed == 0 {
+		fmt.Printf("Glossary term '%s' not found\n", source)
+		return nil
+	}
+	if err := g.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d glossary term(s)\n", removed)
+	return nil
+}
@@ -0,0 +1,258 @@
+package manager
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/SimonGino/i18n-manager/internal/ai"
+	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	defaultKeyStyle     = "dotted"
+	defaultKeyPrefix    = "msg."
+	defaultKeyMaxLength = 50
+)
+
+// defaultStopWords are removed from generated keys when key_remove_stop_words
+// is set.
+var defaultStopWords = []string{
+	"a", "an", "the", "of", "to", "in", "on", "at", "for", "by", "with", "from",
+	"and", "or", "is", "are", "was", "were", "be", "been", "has", "have", "this", "that", "it", "its",
+	"please", "your", "you",
+}
+
+// keyText is the text a key is generated for.
+type keyText struct {
+	Source     string
+	SourceLang string
+	English    string // 英文译文，源语言为英文时即原文
+	File       string // 文本所在的源码文件，只有 extract 会设置
+}
+
+// keyStrategy generates a key, prefix included. Semantic strategies let the
+// AI service choose the category and only use a prefix that was set
+// explicitly.
+type keyStrategy struct {
+	description  string
+	needsEnglish bool
+	semantic     bool
+	generate     func(g *keyGenerator, t keyText, prefix string) (string, error)
+}
+
+// keyStyles lists the strategies in the order they are documented.
+var keyStyles = []string{"dotted", "snake", "camel", "ai", "hash"}
+
+var keyStrategies = map[string]keyStrategy{
+	"dotted": {
+		description:  "English words separated by dots (msg.user.not.found)",
+		needsEnglish: true,
+		generate: func(g *keyGenerator, t keyText, prefix string) (string, error) {
+			return g.fromWords(t, prefix, func(words []string) string { return strings.Join(words, ".") })
+		},
+	},
+	"snake": {
+		description:  "English words in snake_case (msg.user_not_found)",
+		needsEnglish: true,
+		generate: func(g *keyGenerator, t keyText, prefix string) (string, error) {
+			return g.fromWords(t, prefix, func(words []string) string { return strings.Join(words, "_") })
+		},
+	},
+	"camel": {
+		description:  "English words in camelCase (msg.userNotFound)",
+		needsEnglish: true,
+		generate: func(g *keyGenerator, t keyText, prefix string) (string, error) {
+			return g.fromWords(t, prefix, func(words []string) string {
+				for i := 1; i < len(words); i++ {
+					words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+				}
+				return strings.Join(words, "")
+			})
+		},
+	},
+	"ai": {
+		description: "Semantic key suggested by the AI service (error.user.not_found)",
+		semantic:    true,
+		generate: func(g *keyGenerator, t keyText, prefix string) (string, error) {
+			suggested, err := ai.SuggestKey(ai.KeyRequest{
+				Text:       t.Source,
+				SourceLang: t.SourceLang,
+				English:    t.English,
+				Prefix:     prefix,
+			})
+			if err != nil {
+				return "", err
+			}
+			key := strings.Trim(invalidKeyChars.ReplaceAllString(suggested, ""), ".")
+			if key == "" {
+				return "", fmt.Errorf("invalid key suggested: %q", suggested)
+			}
+			if !strings.HasPrefix(key, prefix) {
+				key = prefix + key
+			}
+			return key, nil
+		},
+	},
+	"hash": {
+		description: "Hash of the source text (msg.3f2a9c1e)",
+		generate: func(g *keyGenerator, t keyText, prefix string) (string, error) {
+			return fmt.Sprintf("%s%x", prefix, sha1.Sum([]byte(t.Source)))[:len(prefix)+8], nil
+		},
+	},
+}
+
+// invalidKeyChars matches characters that are not used in suggested keys.
+var invalidKeyChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// keyGenerator creates keys for new texts with the strategy and options from
+// the config and the --key-style and --key-prefix flags.
+type keyGenerator struct {
+	strategy       keyStrategy
+	prefix         string
+	prefixSet      bool // 前缀是在配置或参数中显式指定的
+	modulePrefixes map[string]string
+	src            string
+	maxLength      int
+	stopWords      map[string]bool
+}
+
+// newKeyGenerator returns the generator selected by the config and flags.
+// src is the directory module prefixes are relative to; it is empty when the
+// texts do not come from source files.
+func newKeyGenerator(c *cli.Context, src string) (*keyGenerator, error) {
+	cfg := config.GetConfig()
+	style := cfg.KeyStyle
+	if c.IsSet("key-style") {
+		style = c.String("key-style")
+	}
+	if style == "" {
+		style = defaultKeyStyle
+	}
+	strategy, ok := keyStrategies[style]
+	if !ok {
+		return nil, fmt.Errorf("unknown key style '%s' (valid: %s)", style, strings.Join(keyStyles, ", "))
+	}
+
+	g := &keyGenerator{
+		strategy:       strategy,
+		prefix:         defaultKeyPrefix,
+		modulePrefixes: cfg.KeyModulePrefixes,
+		src:            src,
+		maxLength:      cfg.KeyMaxLength,
+		stopWords:      make(map[string]bool),
+	}
+	if cfg.KeyPrefix != nil {
+		g.prefix, g.prefixSet = *cfg.KeyPrefix, true
+	}
+	if c.IsSet("key-prefix") {
+		g.prefix, g.prefixSet = c.String("key-prefix"), true
+	}
+	if g.maxLength <= 0 {
+		g.maxLength = defaultKeyMaxLength
+	}
+	if cfg.KeyRemoveStopWords {
+		for _, w := range defaultStopWords {
+			g.stopWords[w] = true
+		}
+	}
+	for _, w := range cfg.KeyStopWords {
+		g.stopWords[strings.ToLower(w)] = true
+	}
+	return g, nil
+}
+
+// needsEnglish reports whether keys are generated from the English
+// translation.
+func (g *keyGenerator) needsEnglish() bool {
+	return g.strategy.needsEnglish
+}
+
+// generate returns the key for t, with the part after the prefix cut at the
+// maximum length.
+func (g *keyGenerator) generate(t keyText) (string, error) {
+	prefix, explicit := g.prefix, g.prefixSet
+	if p, ok := g.modulePrefix(t.File); ok {
+		prefix, explicit = p, true
+	}
+	if g.strategy.semantic && !explicit {
+		prefix = ""
+	}
+	key, err := g.strategy.generate(g, t, prefix)
+	if err != nil {
+		return "", err
+	}
+	// 长度限制只作用于前缀之后生成的部分，对所有生成方式都适用
+	if body := strings.TrimPrefix(key, prefix); len(body) > g.maxLength {
+		key = prefix + strings.TrimRight(body[:g.maxLength], "._-")
+	}
+	return key, nil
+}
+
+// modulePrefix returns the prefix configured for the deepest directory that
+// contains file.
+func (g *keyGenerator) modulePrefix(file string) (string, bool) {
+	if file == "" || len(g.modulePrefixes) == 0 {
+		return "", false
+	}
+	rel, err := filepath.Rel(g.src, file)
+	if err != nil {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+
+	best, prefix := -1, ""
+	for dir, p := range g.modulePrefixes {
+		dir = strings.Trim(filepath.ToSlash(dir), "/")
+		if (dir == "" || dir == "." || strings.HasPrefix(rel, dir+"/")) && len(dir) > best {
+			best, prefix = len(dir), p
+		}
+	}
+	return prefix, best >= 0
+}
+
+// words returns the lowercase English words of text without stop words.
+func (g *keyGenerator) words(text string) []string {
+	text = strings.Map(func(r rune) rune {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			return r
+		case unicode.IsSpace(r):
+			return ' '
+		}
+		return -1
+	}, strings.ToLower(text))
+
+	var words []string
+	for _, w := range strings.Fields(text) {
+		if !g.stopWords[w] {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// fromWords joins the words of the English translation.
+func (g *keyGenerator) fromWords(t keyText, prefix string, join func(words []string) string) (string, error) {
+	words := g.words(t.English)
+	if len(words) == 0 {
+		return "", fmt.Errorf("no English words in %q", t.English)
+	}
+	return prefix + join(words), nil
+}
+
+// uniqueKey returns key, or key with a numeric suffix if it is taken.
+func uniqueKey(key string, taken map[string]bool) string {
+	if !taken[key] {
+		return key
+	}
+	for n := 2; ; n++ {
+		if candidate := fmt.Sprintf("%s.%d", key, n); !taken[candidate] {
+			return candidate
+		}
+	}
+}
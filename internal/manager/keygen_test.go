@@ -0,0 +1,99 @@
+package manager
+
+import "testing"
+
+func TestGenerateKey(t *testing.T) {
+	setupProject(t, nil)
+
+	tests := []struct {
+		name      string
+		style     string
+		prefix    string
+		explicit  bool
+		maxLength int
+		text      keyText
+		want      string
+	}{
+		{
+			name:      "dotted",
+			style:     "dotted",
+			prefix:    "msg.",
+			maxLength: 50,
+			text:      keyText{Source: "用户不存在", English: "User not found!"},
+			want:      "msg.user.not.found",
+		},
+		{
+			name:      "snake cut at a separator",
+			style:     "snake",
+			prefix:    "msg.",
+			maxLength: 9,
+			text:      keyText{Source: "用户不存在", English: "User not found"},
+			want:      "msg.user_not",
+		},
+		{
+			name:      "camel",
+			style:     "camel",
+			prefix:    "",
+			maxLength: 8,
+			text:      keyText{Source: "用户不存在", English: "User not found"},
+			want:      "userNotF",
+		},
+		{
+			name:      "ai",
+			style:     "ai",
+			maxLength: 50,
+			text:      keyText{Source: "保存", SourceLang: "zh"},
+			want:      "button.save_the_current_document",
+		},
+		{
+			name:      "ai cut",
+			style:     "ai",
+			maxLength: 16,
+			text:      keyText{Source: "保存", SourceLang: "zh"},
+			want:      "button.save_the",
+		},
+		{
+			name:      "ai with explicit prefix",
+			style:     "ai",
+			prefix:    "app.",
+			explicit:  true,
+			maxLength: 5,
+			text:      keyText{Source: "删除", SourceLang: "zh"},
+			want:      "app.mock",
+		},
+		{
+			name:      "hash",
+			style:     "hash",
+			prefix:    "msg.",
+			maxLength: 50,
+			text:      keyText{Source: "保存"},
+			want:      "msg.fadf24db",
+		},
+		{
+			name:      "hash cut",
+			style:     "hash",
+			prefix:    "msg.",
+			maxLength: 4,
+			text:      keyText{Source: "保存"},
+			want:      "msg.fadf",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &keyGenerator{
+				strategy:  keyStrategies[tt.style],
+				prefix:    tt.prefix,
+				prefixSet: tt.explicit,
+				maxLength: tt.maxLength,
+				stopWords: map[string]bool{},
+			}
+			got, err := g.generate(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
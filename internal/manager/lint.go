@@ -0,0 +1,224 @@
+package manager
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+// Severities of lint rules.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+	severityOff     = "off"
+)
+
+// lintValue is one value of a key in one language, together with the
+// source-language value it was translated from.
+type lintValue struct {
+	Key      string
+	Lang     string
+	Value    string
+	Source   string
+	IsSource bool
+}
+
+// lintRule is a single check. Key rules look at every key once, value rules
+// at every value in every language. Rules that can repair a value provide
+// fix. The checks return an empty message if there is nothing to report.
+type lintRule struct {
+	name        string
+	description string
+	severity    string
+	pattern     *regexp.Regexp
+	max         int
+
+	checkKey   func(r *lintRule, key string) string
+	checkValue func(r *lintRule, v lintValue) string
+	fix        func(value string) string
+}
+
+// lintIssue is a problem found in one entry of a properties file.
+type lintIssue struct {
+	File     string
+	Line     int
+	Key      string
+	Rule     string
+	Severity string
+	Message  string
+}
+
+func (i lintIssue) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s: %s [%s]", i.File, i.Line, i.Severity, i.Key, i.Message, i.Rule)
+}
+
+// configuredLintRules returns the rules with the severities and parameters
+// from the lint section of the config applied.
+func configuredLintRules() ([]*lintRule, error) {
+	overrides := config.GetConfig().Lint
+	rules := defaultLintRules()
+
+	known := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		known[r.name] = true
+		o, ok := overrides[r.name]
+		if !ok {
+			continue
+		}
+		switch o.Severity {
+		case "":
+		case severityError, severityWarning, severityOff:
+			r.severity = o.Severity
+		default:
+			return nil, fmt.Errorf("invalid severity '%s' for lint rule '%s' (valid: error, warning, off)", o.Severity, r.name)
+		}
+		if o.Pattern != "" {
+			re, err := regexp.Compile(o.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern for lint rule '%s': %v", r.name, err)
+			}
+			r.pattern = re
+		}
+		if o.Max > 0 {
+			r.max = o.Max
+		}
+	}
+
+	for name := range overrides {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown lint rule '%s'", name)
+		}
+	}
+	return rules, nil
+}
+
+func printLintRules(rules []*lintRule) {
+	for _, r := range rules {
+		fmt.Printf("%-20s %-8s %s\n", r.name, r.severity, r.description)
+	}
+}
+
+func HandleLint(c *cli.Context) error {
+	rules, err := configuredLintRules()
+	if err != nil {
+		return err
+	}
+	if c.Bool("list-rules") {
+		printLintRules(rules)
+		return nil
+	}
+
+	sourceLang := config.GetSourceLang()
+	if sourceLang == nil {
+		return fmt.Errorf("no source language configured")
+	}
+	translations, err := loadAllTranslations()
+	if err != nil {
+		return fmt.Errorf("error loading translations: %v", err)
+	}
+
+	fix := c.Bool("fix")
+	tx := newTransaction()
+	fixed := 0
+	var issues []lintIssue
+	for _, t := range translations {
+		// 键的问题只报告一次，位置优先取源语言文件
+		keyLang := definingLang(t, sourceLang.Code)
+		for _, r := range rules {
+			if r.severity == severityOff || r.checkKey == nil {
+				continue
+			}
+			if msg := r.checkKey(r, t.Key); msg != "" {
+				issues = append(issues, lintIssue{
+					File:     config.GetPropertiesFilePath(keyLang),
+					Line:     t.Lines[keyLang],
+					Key:      t.Key,
+					Rule:     r.name,
+					Severity: r.severity,
+					Message:  msg,
+				})
+			}
+		}
+
+		for _, mapping := range config.GetConfig().Language.Mappings {
+			value, ok := t.Values[mapping.Code]
+			if !ok {
+				continue
+			}
+			v := lintValue{Key: t.Key, Lang: mapping.Code, Value: value, IsSource: mapping.IsSource}
+			if !mapping.IsSource {
+				v.Source = t.Values[sourceLang.Code]
+			}
+
+			filename := config.GetPropertiesFilePath(mapping.Code)
+			changed := false
+			for _, r := range rules {
+				if r.severity == severityOff || r.checkValue == nil {
+					continue
+				}
+				msg := r.checkValue(r, v)
+				if msg == "" {
+					continue
+				}
+				if fix && r.fix != nil {
+					v.Value = r.fix(v.Value)
+					changed = true
+					continue
+				}
+				issues = append(issues, lintIssue{
+					File:     filename,
+					Line:     t.Lines[mapping.Code],
+					Key:      t.Key,
+					Rule:     r.name,
+					Severity: r.severity,
+					Message:  msg,
+				})
+			}
+
+			if changed {
+				doc, err := tx.document(filename)
+				if err != nil {
+					return err
+				}
+				doc.Set(t.Key, v.Value)
+				fixed++
+			}
+		}
+	}
+
+	if fixed > 0 {
+		if _, err := tx.commit(); err != nil {
+			return fmt.Errorf("error saving translations: %v", err)
+		}
+		fmt.Printf("Fixed %d value(s)\n", fixed)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	errors := 0
+	for _, issue := range issues {
+		fmt.Println(issue)
+		if issue.Severity == severityError {
+			errors++
+		}
+	}
+
+	if len(issues) == 0 {
+		if fixed == 0 {
+			fmt.Println("No issues found")
+		}
+		return nil
+	}
+	fmt.Printf("\nFound %d issue(s), %d error(s)\n", len(issues), errors)
+	if errors > 0 {
+		return fmt.Errorf("lint failed: %d error(s) found", errors)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package manager
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/SimonGino/i18n-manager/internal/placeholder"
+)
+
+const (
+	defaultKeyPattern = `^[a-z][a-zA-Z0-9_-]*(\.[a-zA-Z0-9_-]+)*$`
+	defaultKeyDepth   = 6
+	defaultMaxLength  = 200
+)
+
+// defaultLintRules returns a fresh copy of the built-in rules with their
+// default settings.
+func defaultLintRules() []*lintRule {
+	return []*lintRule{
+		{
+			name:        "key-pattern",
+			description: "Keys must match a naming pattern",
+			severity:    severityWarning,
+			pattern:     regexp.MustCompile(defaultKeyPattern),
+			checkKey: func(r *lintRule, key string) string {
+				if r.pattern.MatchString(key) {
+					return ""
+				}
+				return fmt.Sprintf("key does not match %s", r.pattern)
+			},
+		},
+		{
+			name:        "key-depth",
+			description: "Keys must not have too many dot-separated segments",
+			severity:    severityWarning,
+			max:         defaultKeyDepth,
+			checkKey: func(r *lintRule, key string) string {
+				if depth := strings.Count(key, ".") + 1; depth > r.max {
+					return fmt.Sprintf("key has %d segments, more than %d", depth, r.max)
+				}
+				return ""
+			},
+		},
+		{
+			name:        "trailing-whitespace",
+			description: "Values must not end with whitespace",
+			severity:    severityWarning,
+			checkValue: func(r *lintRule, v lintValue) string {
+				if v.Value != trimTrailingSpace(v.Value) {
+					return "value ends with whitespace"
+				}
+				return ""
+			},
+			fix: trimTrailingSpace,
+		},
+		{
+			name:        "untranslated",
+			description: "Translations must differ from the source-language value",
+			severity:    severityWarning,
+			checkValue: func(r *lintRule, v lintValue) string {
+				if v.IsSource || v.Value != v.Source || !strings.ContainsFunc(v.Value, unicode.IsLetter) {
+					return ""
+				}
+				// 简繁中文的很多文本本来就相同，由 zh-tw-simplified 规则检查
+				if isChinese(v.Lang) {
+					return ""
+				}
+				return "value is identical to the source language, probably untranslated"
+			},
+		},
+		{
+			name:        "zh-punctuation",
+			description: "Chinese text must use full-width punctuation",
+			severity:    severityWarning,
+			checkValue: func(r *lintRule, v lintValue) string {
+				if !isChinese(v.Lang) {
+					return ""
+				}
+				if found := halfWidthPunctuation(v.Value); len(found) > 0 {
+					return fmt.Sprintf("half-width punctuation %s after Chinese text", quoteRunes(found))
+				}
+				return ""
+			},
+			fix: fixHalfWidthPunctuation,
+		},
+		{
+			name:        "zh-tw-simplified",
+			description: "Traditional Chinese must not contain Simplified characters",
+			severity:    severityWarning,
+			checkValue: func(r *lintRule, v lintValue) string {
+				if v.Lang != "zh_TW" && v.Lang != "zh_HK" {
+					return ""
+				}
+				if found := simplifiedChars(v.Value); len(found) > 0 {
+					return fmt.Sprintf("Simplified characters in Traditional Chinese: %s", quoteRunes(found))
+				}
+				return ""
+			},
+		},
+		{
+			name:        "html",
+			description: "HTML tags must be balanced",
+			severity:    severityError,
+			checkValue: func(r *lintRule, v lintValue) string {
+				return checkHTML(v.Value)
+			},
+		},
+		{
+			name:        "max-length",
+			description: "Values must not be too long",
+			severity:    severityWarning,
+			max:         defaultMaxLength,
+			checkValue: func(r *lintRule, v lintValue) string {
+				if n := utf8.RuneCountInString(v.Value); n > r.max {
+					return fmt.Sprintf("value has %d characters, more than %d", n, r.max)
+				}
+				return ""
+			},
+		},
+		{
+			name:        "apostrophe",
+			description: "Single quotes must be doubled in MessageFormat patterns",
+			severity:    severityError,
+			checkValue: func(r *lintRule, v lintValue) string {
+				if hasUnescapedApostrophes(v.Value) {
+					return "single quotes must be doubled ('') in MessageFormat patterns"
+				}
+				return ""
+			},
+			fix: placeholder.EscapeApostrophes,
+		},
+	}
+}
+
+func isChinese(lang string) bool {
+	return lang == "zh" || strings.HasPrefix(lang, "zh_")
+}
+
+func trimTrailingSpace(s string) string {
+	return strings.TrimRightFunc(s, unicode.IsSpace)
+}
+
+func quoteRunes(runes []rune) string {
+	quoted := make([]string, len(runes))
+	for i, r := range runes {
+		quoted[i] = fmt.Sprintf("'%c'", r)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// fullWidth maps half-width punctuation to the full-width form used in
+// Chinese text.
+var fullWidth = map[rune]rune{',': '，', ';': '；', ':': '：', '!': '！', '?': '？'}
+
+// halfWidthPunctuation returns the distinct half-width punctuation marks that
+// directly follow a Chinese character.
+func halfWidthPunctuation(s string) []rune {
+	var found []rune
+	prev := rune(0)
+	for _, r := range s {
+		if _, ok := fullWidth[r]; ok && unicode.Is(unicode.Han, prev) && !strings.ContainsRune(string(found), r) {
+			found = append(found, r)
+		}
+		prev = r
+	}
+	return found
+}
+
+func fixHalfWidthPunctuation(s string) string {
+	var b strings.Builder
+	prev := rune(0)
+	for _, r := range s {
+		if full, ok := fullWidth[r]; ok && unicode.Is(unicode.Han, prev) {
+			b.WriteRune(full)
+		} else {
+			b.WriteRune(r)
+		}
+		prev = r
+	}
+	return b.String()
+}
+
+// simplifiedOnly lists common Simplified characters whose Traditional form
+// differs. Characters that are also standard Traditional characters with a
+// meaning of their own, such as 后 (queen), 胜, 适, 种, 冲 and 却, are left out.
+const simplifiedOnly = "这们个为来时会说对没进过还发问无与东车长开关门见机电话语请认记设证误错户务输传网络页显选择项态权检" +
+	"账号码验确载数据库图标题档资讯线级类业单双应帮处节统织经给结续练组细终总编缓变让试该详读课调谈谢边达运远" +
+	"连递遗钮钱铁链销锁键镜闭间闻阅队阶际陆隐难颜风飞饭马驱鱼鸟点热爱产亲仅从众优伤体侧储儿兑写决况净减则" +
+	"刚创刷剧办动劳势区医华协卖卫厂历压县参叙吗启员响团园围场块坏坚执扩扫扬报担拥挂换损摇摄敌断旧晓术杀杂" +
+	"条极构栏树样桥欢毕汇沟浅测济浏灭灯灵烦烧状独环现画畅疗盖监盘离积称稳穷笔筛签简粮纠红约纪纯纸绕绘络绝" +
+	"继绩维综绿缩罗职联聊脑脚荐获营虑补装观规视览觉订计讨议讲许论访评识诉词译诚谁负货质购费赛趋跃轨转轮软" +
+	"轻较辑辖迟逻邮邻释钟钥铃银闲阳阴陈随险须顶顺预领频饮馆驾齐龙"
+
+func simplifiedChars(s string) []rune {
+	var found []rune
+	for _, r := range s {
+		if strings.ContainsRune(simplifiedOnly, r) && !strings.ContainsRune(string(found), r) {
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+var (
+	htmlTagPattern = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9]*)(?:\s[^<>]*)?(/?)>`)
+	voidElements   = map[string]bool{
+		"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+		"img": true, "input": true, "link": true, "meta": true, "source": true, "wbr": true,
+	}
+)
+
+// checkHTML reports the first unbalanced HTML tag of s.
+func checkHTML(s string) string {
+	var open []string
+	for _, m := range htmlTagPattern.FindAllStringSubmatch(s, -1) {
+		closing, name, selfClosing := m[1] == "/", strings.ToLower(m[2]), m[3] == "/"
+		if voidElements[name] || selfClosing {
+			continue
+		}
+		if !closing {
+			open = append(open, name)
+			continue
+		}
+		if len(open) == 0 || open[len(open)-1] != name {
+			return fmt.Sprintf("unexpected </%s>", name)
+		}
+		open = open[:len(open)-1]
+	}
+	if len(open) > 0 {
+		return fmt.Sprintf("unclosed <%s>", open[len(open)-1])
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package manager
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+)
+
+func TestLintRules(t *testing.T) {
+	cfg := config.GetConfig()
+	saved := *cfg
+	t.Cleanup(func() { *cfg = saved })
+	cfg.Apostrophes = ""
+
+	rules := make(map[string]*lintRule)
+	for _, r := range defaultLintRules() {
+		rules[r.name] = r
+	}
+
+	tests := []struct {
+		rule  string
+		key   string
+		value lintValue
+		want  string // 期望消息中包含的文本，空表示没有问题
+		fixed string
+	}{
+		{rule: "key-pattern", key: "user.delete_all"},
+		{rule: "key-pattern", key: "User.Delete", want: "does not match"},
+		{rule: "key-pattern", key: "user..delete", want: "does not match"},
+		{rule: "key-depth", key: "a.b.c.d.e.f"},
+		{rule: "key-depth", key: "a.b.c.d.e.f.g", want: "7 segments"},
+
+		{rule: "trailing-whitespace", value: lintValue{Value: "Save"}},
+		{rule: "trailing-whitespace", value: lintValue{Value: "Save \t"}, want: "ends with whitespace", fixed: "Save"},
+
+		{rule: "untranslated", value: lintValue{Lang: "en", Value: "Save", Source: "保存"}},
+		{rule: "untranslated", value: lintValue{Lang: "en", Value: "保存", Source: "保存"}, want: "identical"},
+		{rule: "untranslated", value: lintValue{Lang: "en", Value: "{0}", Source: "{0}"}},
+		{rule: "untranslated", value: lintValue{Lang: "zh_TW", Value: "保存", Source: "保存"}},
+		{rule: "untranslated", value: lintValue{Lang: "zh", Value: "保存", IsSource: true}},
+
+		{rule: "zh-punctuation", value: lintValue{Lang: "zh", Value: "保存，删除？"}},
+		{rule: "zh-punctuation", value: lintValue{Lang: "zh", Value: "版本 v1.0, OK"}},
+		{rule: "zh-punctuation", value: lintValue{Lang: "zh", Value: "保存,删除?"}, want: "',', '?'", fixed: "保存，删除？"},
+		{rule: "zh-punctuation", value: lintValue{Lang: "en", Value: "保存,删除"}},
+
+		{rule: "zh-tw-simplified", value: lintValue{Lang: "zh_TW", Value: "儲存設定"}},
+		{rule: "zh-tw-simplified", value: lintValue{Lang: "zh_TW", Value: "皇后之後，勝利"}},
+		{rule: "zh-tw-simplified", value: lintValue{Lang: "zh_HK", Value: "保存设置"}, want: "'设'"},
+		{rule: "zh-tw-simplified", value: lintValue{Lang: "zh", Value: "保存设置"}},
+
+		{rule: "html", value: lintValue{Value: "<b>Bold</b><br>a<br/><img src=x>"}},
+		{rule: "html", value: lintValue{Value: "<b>Bold"}, want: "unclosed <b>"},
+		{rule: "html", value: lintValue{Value: "<b><i>x</b></i>"}, want: "unexpected </b>"},
+
+		{rule: "max-length", value: lintValue{Value: strings.Repeat("长", 200)}},
+		{rule: "max-length", value: lintValue{Value: strings.Repeat("长", 201)}, want: "201 characters"},
+
+		{rule: "apostrophe", value: lintValue{Value: "Don't save"}},
+		{rule: "apostrophe", value: lintValue{Value: "Don''t delete {0}"}},
+		{rule: "apostrophe", value: lintValue{Value: "Don't delete {0}"}, want: "doubled", fixed: "Don''t delete {0}"},
+	}
+	for _, tt := range tests {
+		r := rules[tt.rule]
+		var got string
+		if r.checkKey != nil {
+			got = r.checkKey(r, tt.key)
+		} else {
+			got = r.checkValue(r, tt.value)
+		}
+		name := tt.rule + " " + tt.key + tt.value.Value
+		if (got == "") != (tt.want == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", name, got, tt.want)
+		}
+		if tt.fixed != "" {
+			if r.fix == nil {
+				t.Errorf("%s: rule has no fix", name)
+			} else if fixed := r.fix(tt.value.Value); fixed != tt.fixed {
+				t.Errorf("%s: fixed to %q, want %q", name, fixed, tt.fixed)
+			}
+		}
+	}
+}
+
+func TestLintFix(t *testing.T) {
+	dir := setupProject(t, map[string]string{
+		"messages.properties":       "a=Save \nb=Don't delete {0}\nc=OK\n",
+		"messages_zh.properties":    "a=\\u4fdd\\u5b58\nb=\\u5220\\u9664{0}\nc=\\u597d,\\u7684\n",
+		"messages_zh_TW.properties": "# Traditional\na=\\u5132\\u5b58\n",
+	})
+
+	run(t, HandleLint, "--fix")
+
+	want := map[string]string{
+		"messages.properties":       "a=Save\nb=Don''t delete {0}\nc=OK\n",
+		"messages_zh.properties":    "a=\\u4fdd\\u5b58\nb=\\u5220\\u9664{0}\nc=\\u597d\\uff0c\\u7684\n",
+		"messages_zh_TW.properties": "# Traditional\na=\\u5132\\u5b58\n",
+	}
+	for name, content := range want {
+		if got := readFile(t, dir, name); got != content {
+			t.Errorf("%s\n got: %q\nwant: %q", name, got, content)
+		}
+	}
+}
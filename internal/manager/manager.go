@@ -1,20 +1,21 @@
 package manager
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-	"strconv"
+	"sort"
 	"strings"
 
 	"github.com/SimonGino/i18n-manager/internal/ai"
 	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/SimonGino/i18n-manager/internal/properties"
 	"github.com/urfave/cli/v2"
 )
 
 type Translation struct {
 	Key    string
 	Values map[string]string
+	Lines  map[string]int // 每种语言中生效定义所在的行号
 }
 
 func HandleTranslate(c *cli.Context) error {
@@ -41,60 +42,125 @@ func HandleTranslate(c *cli.Context) error {
 
 	// Save source language text
 	translations[sourceLang.Code] = text
+	order := []string{sourceLang.Code}
 
 	// If source language is zh, also save for zh_CN
 	if sourceLang.Code == "zh" {
 		translations["zh_CN"] = text
+		order = append(order, "zh_CN")
 	}
 
-	// If no key provided, translate to English first for key generation
-	if key == "" {
-		// Get English translation for key generation
-		englishText, err := ai.Translate(ai.TranslationRequest{
-			Text:       text,
-			SourceLang: sourceLang.Code,
-			TargetLang: "en",
-		})
+	onConflict := c.String("on-conflict")
+
+	// 翻译前先查找原文相同或相近的已有键，避免同一文本以不同的键重复添加
+	if !c.Bool("allow-duplicate") {
+		match, err := suggestExistingKey(c, text, key, sourceLang.Code)
 		if err != nil {
-			return fmt.Errorf("failed to generate key: %v", err)
+			return err
+		}
+		if match != nil {
+			// 相近的原文不能沿用本文本的译文，只使用已有的键
+			if match.Value != text {
+				return nil
+			}
+			// 原文相同时沿用已有的键，只翻译并补充它缺少的语言
+			key, onConflict = match.Key, conflictReuse
+			var missing []config.LangMapping
+			for _, targetLang := range targetLangs {
+				if _, ok := match.Values[targetLang.Code]; !ok {
+					missing = append(missing, targetLang)
+				}
+			}
+			if len(missing) == 0 {
+				fmt.Printf("Key '%s' already has all these translations, nothing to add\n", key)
+				return nil
+			}
+			targetLangs = missing
+		}
+	}
+
+	var keys *keyGenerator
+	if key == "" {
+		var err error
+		if keys, err = newKeyGenerator(c, ""); err != nil {
+			return err
 		}
-		translations["en"] = englishText
-		key = generateKey(englishText)
 	}
 
-	// Translate to other target languages
+	// Translate to every target language concurrently. If no key is provided
+	// and the key style needs it, English is always requested since the key
+	// is generated from it.
+	var reqs []ai.TranslationRequest
+	needEnglish := keys != nil && keys.needsEnglish() && sourceLang.Code != "en"
 	for _, targetLang := range targetLangs {
-		if targetLang.Code == "en" && translations["en"] != "" {
-			continue // Skip if English translation already exists
+		if targetLang.Code == "en" {
+			needEnglish = false
 		}
-		translated, err := ai.Translate(ai.TranslationRequest{
+		reqs = append(reqs, ai.TranslationRequest{
 			Text:       text,
 			SourceLang: sourceLang.Code,
 			TargetLang: targetLang.Code,
 		})
+	}
+	if needEnglish {
+		reqs = append(reqs, ai.TranslationRequest{
+			Text:       text,
+			SourceLang: sourceLang.Code,
+			TargetLang: "en",
+		})
+	}
+
+	results, errs := ai.TranslateAll(reqs)
+	for i, req := range reqs {
+		if errs[i] != nil {
+			if req.TargetLang == "en" && keys != nil && keys.needsEnglish() {
+				return fmt.Errorf("failed to generate key: %v", errs[i])
+			}
+			return fmt.Errorf("error translating to %s: %v", req.TargetLang, errs[i])
+		}
+		translations[req.TargetLang] = results[i]
+		order = append(order, req.TargetLang)
+	}
+
+	explicit := key != ""
+	if !explicit {
+		var err error
+		key, err = keys.generate(keyText{Source: text, SourceLang: sourceLang.Code, English: translations["en"]})
 		if err != nil {
-			return fmt.Errorf("error translating to %s: %v", targetLang.Code, err)
+			return fmt.Errorf("failed to generate key: %v", err)
 		}
-		translations[targetLang.Code] = translated
+	}
+
+	// 键已存在时先解决冲突，避免覆盖含义不同的已有翻译
+	key, translations, err := resolveKeyConflict(c, key, explicit, sourceLang.Code, translations, onConflict)
+	if err != nil {
+		return err
+	}
+	if translations == nil {
+		fmt.Println("Translation cancelled")
+		return nil
+	}
+	if len(translations) == 0 {
+		fmt.Printf("Key '%s' already has all these translations, nothing to add\n", key)
+		return nil
 	}
 
 	// Print translations to be added
 	fmt.Printf("\nTranslations to be added:\n")
 	fmt.Printf("Key: %s\n", key)
-	for lang, value := range translations {
-		fmt.Printf("%s: %s\n", lang, value)
+	for _, lang := range order {
+		if value, ok := translations[lang]; ok {
+			fmt.Printf("%s: %s\n", lang, value)
+		}
 	}
-
-	// Ask for confirmation
-	fmt.Print("\nDo you want to add these translations? (y/N): ")
-	var response string
-	if _, err := fmt.Scanln(&response); err != nil {
-		// 如果用户直接按回车，Scanln 会返回错误，这种情况我们视为取消操作
-		fmt.Println("Translation cancelled")
-		return nil
+	for i, req := range reqs {
+		if _, ok := translations[req.TargetLang]; ok {
+			printGlossaryWarnings(req, results[i], req.TargetLang)
+		}
 	}
 
-	if strings.ToLower(response) != "y" {
+	// Ask for confirmation
+	if !c.Bool("yes") && !confirm("\nDo you want to add these translations? (y/N): ") {
 		fmt.Println("Translation cancelled")
 		return nil
 	}
@@ -107,6 +173,17 @@ func HandleTranslate(c *cli.Context) error {
 	return nil
 }
 
+// confirm asks a yes/no question and reports whether the user answered "y".
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	var response string
+	if _, err := fmt.Scanln(&response); err != nil {
+		// 如果用户直接按回车，Scanln 会返回错误，这种情况我们视为取消操作
+		return false
+	}
+	return strings.ToLower(response) == "y"
+}
+
 func HandleAdd(c *cli.Context) error {
 	key := c.String("key")
 	if key == "" {
@@ -134,23 +211,6 @@ func HandleAdd(c *cli.Context) error {
 	return nil
 }
 
-func decodeUnicode(s string) string {
-	var result string
-	for len(s) > 0 {
-		if strings.HasPrefix(s, "\\u") && len(s) >= 6 {
-			code, err := strconv.ParseInt(s[2:6], 16, 32)
-			if err == nil {
-				result += string(rune(code))
-				s = s[6:]
-				continue
-			}
-		}
-		result += string(s[0])
-		s = s[1:]
-	}
-	return result
-}
-
 func HandleList(c *cli.Context) error {
 	translations, err := loadAllTranslations()
 	if err != nil {
@@ -164,8 +224,7 @@ func HandleList(c *cli.Context) error {
 			if t.Key == key {
 				fmt.Printf("Key: %s\n", t.Key)
 				for lang, value := range t.Values {
-					decodedValue := decodeUnicode(value)
-					fmt.Printf("  %s: %s\n", lang, decodedValue)
+					fmt.Printf("  %s: %s\n", lang, value)
 				}
 				found = true
 				break
@@ -181,8 +240,7 @@ func HandleList(c *cli.Context) error {
 	for _, t := range translations {
 		fmt.Printf("Key: %s\n", t.Key)
 		for lang, value := range t.Values {
-			decodedValue := decodeUnicode(value)
-			fmt.Printf("  %s: %s\n", lang, decodedValue)
+			fmt.Printf("  %s: %s\n", lang, value)
 		}
 		fmt.Println()
 	}
@@ -190,64 +248,23 @@ func HandleList(c *cli.Context) error {
 	return nil
 }
 
-func HandleCheck(c *cli.Context) error {
-	translations, err := loadAllTranslations()
-	if err != nil {
-		return fmt.Errorf("error loading translations: %v", err)
-	}
-
-	cfg := config.GetConfig()
-	var missingCount int
+// missingTranslation is a key that has no value in one of the languages.
+type missingTranslation struct {
+	Key  string
+	Lang string
+}
 
+// findMissing returns every key/language pair without a translation.
+func findMissing(translations []Translation) []missingTranslation {
+	var missing []missingTranslation
 	for _, t := range translations {
-		for _, mapping := range cfg.Language.Mappings {
+		for _, mapping := range config.GetConfig().Language.Mappings {
 			if _, ok := t.Values[mapping.Code]; !ok {
-				fmt.Printf("Missing translation for key '%s' in language '%s'\n", t.Key, mapping.Code)
-				missingCount++
+				missing = append(missing, missingTranslation{Key: t.Key, Lang: mapping.Code})
 			}
 		}
 	}
-
-	if missingCount == 0 {
-		fmt.Println("All translations are complete!")
-	} else {
-		fmt.Printf("Found %d missing translations\n", missingCount)
-	}
-
-	return nil
-}
-
-func generateKey(text string) string {
-	// 将文本转换为小写
-	key := strings.ToLower(text)
-
-	// 移除标点符号和特殊字符
-	key = strings.Map(func(r rune) rune {
-		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == ' ' {
-			return r
-		}
-		return -1
-	}, key)
-
-	// 将空格替换为点号
-	key = strings.ReplaceAll(key, " ", ".")
-
-	// 处理连续的点号
-	for strings.Contains(key, "..") {
-		key = strings.ReplaceAll(key, "..", ".")
-	}
-
-	// 移除开头和结尾的点号
-	key = strings.Trim(key, ".")
-
-	// 限制长度
-	if len(key) > 50 {
-		key = key[:50]
-		// 确保不以点号结尾
-		key = strings.TrimRight(key, ".")
-	}
-
-	return "msg." + key
+	return missing
 }
 
 func loadAllTranslations() ([]Translation, error) {
@@ -256,42 +273,21 @@ func loadAllTranslations() ([]Translation, error) {
 
 	// 遍历所有语言文件
 	for _, mapping := range cfg.Language.Mappings {
-		filename := config.GetPropertiesFilePath(mapping.Code)
-		file, err := os.Open(filename)
-		if os.IsNotExist(err) {
-			continue
-		}
+		doc, err := loadDocument(config.GetPropertiesFilePath(mapping.Code))
 		if err != nil {
-			return nil, fmt.Errorf("error opening %s: %v", filename, err)
+			return nil, err
 		}
-		defer file.Close()
 
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
-			if line == "" || strings.HasPrefix(line, "#") {
-				continue
-			}
-
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) != 2 {
-				continue
-			}
-
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-
-			if _, ok := translations[key]; !ok {
-				translations[key] = &Translation{
-					Key:    key,
+		for _, entry := range doc.Entries() {
+			if _, ok := translations[entry.Key]; !ok {
+				translations[entry.Key] = &Translation{
+					Key:    entry.Key,
 					Values: make(map[string]string),
+					Lines:  make(map[string]int),
 				}
 			}
-			translations[key].Values[mapping.Code] = value
-		}
-
-		if err := scanner.Err(); err != nil {
-			return nil, fmt.Errorf("error reading %s: %v", filename, err)
+			translations[entry.Key].Values[mapping.Code] = entry.Value
+			translations[entry.Key].Lines[mapping.Code] = entry.Line
 		}
 	}
 
@@ -299,28 +295,20 @@ func loadAllTranslations() ([]Translation, error) {
 	for _, t := range translations {
 		result = append(result, *t)
 	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
 	return result, nil
 }
 
-// Convert Chinese characters to Unicode escape sequences
-func encodeToUnicode(s string) string {
-	var result strings.Builder
-	for _, r := range s {
-		if r > 127 {
-			result.WriteString(fmt.Sprintf("\\u%04x", r))
-		} else {
-			result.WriteRune(r)
-		}
-	}
-	return result.String()
-}
-
+// saveTranslations sets key in every language file that has a translation.
+// All files are updated together or not at all.
 func saveTranslations(key string, translations map[string]string) error {
 	cfg := config.GetConfig()
+	tx := newTransaction()
 
 	// Process each configured language mapping
 	for _, mapping := range cfg.Language.Mappings {
-		filename := config.GetPropertiesFilePath(mapping.Code)
 		value, exists := translations[mapping.Code]
 
 		// Skip if no translation provided for this language
@@ -328,91 +316,28 @@ func saveTranslations(key string, translations map[string]string) error {
 			continue
 		}
 
-		// Read existing file content while preserving order
-		var lines []string
-		file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0644)
+		doc, err := tx.document(config.GetPropertiesFilePath(mapping.Code))
 		if err != nil {
-			return fmt.Errorf("error opening %s: %v", filename, err)
-		}
-
-		scanner := bufio.NewScanner(file)
-		keyFound := false
-		for scanner.Scan() {
-			line := scanner.Text() // Don't trim to preserve formatting
-			if line == "" || strings.HasPrefix(line, "#") {
-				lines = append(lines, line)
-				continue
-			}
-
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) != 2 {
-				lines = append(lines, line)
-				continue
-			}
-
-			currentKey := strings.TrimSpace(parts[0])
-			if currentKey == key {
-				// Encode value if it's Chinese
-				if strings.Contains(mapping.Code, "zh") {
-					value = encodeToUnicode(value)
-				}
-				lines = append(lines, fmt.Sprintf("%s=%s", currentKey, value))
-				keyFound = true
-			} else {
-				lines = append(lines, line)
-			}
-		}
-
-		if err := scanner.Err(); err != nil {
-			file.Close()
-			return fmt.Errorf("error reading %s: %v", filename, err)
-		}
-
-		// If key not found, append it to the end
-		if !keyFound {
-			// Encode value if it's Chinese
-			if strings.Contains(mapping.Code, "zh") {
-				value = encodeToUnicode(value)
-			}
-			lines = append(lines, fmt.Sprintf("%s=%s", key, value))
-		}
-
-		// Rewrite the file
-		if err := file.Truncate(0); err != nil {
-			file.Close()
-			return fmt.Errorf("error truncating %s: %v", filename, err)
-		}
-
-		if _, err := file.Seek(0, 0); err != nil {
-			file.Close()
-			return fmt.Errorf("error seeking in %s: %v", filename, err)
-		}
-
-		writer := bufio.NewWriter(file)
-		for i, line := range lines {
-			if i > 0 || line != "" { // Skip initial empty line
-				if i < len(lines)-1 {
-					if _, err := fmt.Fprintln(writer, line); err != nil {
-						file.Close()
-						return fmt.Errorf("error writing to %s: %v", filename, err)
-					}
-				} else {
-					// 最后一行不添加换行符
-					if _, err := fmt.Fprint(writer, line); err != nil {
-						file.Close()
-						return fmt.Errorf("error writing to %s: %v", filename, err)
-					}
-				}
-			}
+			return err
 		}
+		doc.Set(key, prepareValue(mapping.Code, key, value))
+	}
 
-		if err := writer.Flush(); err != nil {
-			file.Close()
-			return fmt.Errorf("error flushing %s: %v", filename, err)
-		}
+	_, err := tx.commit()
+	return err
+}
 
-		file.Close()
+// loadDocument reads a properties file, returning an empty document if it
+// does not exist yet.
+func loadDocument(filename string) (*properties.Document, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading %s: %v", filename, err)
 	}
 
-	return nil
+	doc, err := properties.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", filename, err)
+	}
+	return doc, nil
 }
@@ -0,0 +1,279 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SimonGino/i18n-manager/internal/ai"
+	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+const mockFixture = `{
+  "en": { "保存": "Save", "删除{0}": "Delete {0}", "用户{0}不存在": "User {0} not found" },
+  "zh_TW": { "保存": "儲存", "删除{0}": "刪除{0}" },
+  "key": { "保存": "button.save_the_current_document" }
+}`
+
+// setupProject creates a bundle directory and configures the mock provider
+// with file paths inside it, so that nothing depends on the working
+// directory. The configuration and translator are restored afterwards.
+func setupProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	fixture := filepath.Join(dir, "fixture.json")
+	if err := os.WriteFile(fixture, []byte(mockFixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.GetConfig()
+	saved := *cfg
+	t.Cleanup(func() {
+		*cfg = saved
+		ai.ResetTranslator()
+	})
+
+	*cfg = config.Config{
+		Provider:     "mock",
+		MockFixture:  fixture,
+		MaxBackups:   -1,
+		GlossaryFile: filepath.Join(dir, "glossary.json"),
+		Language: config.LanguageConfig{
+			FilePattern: filepath.Join(dir, "messages%s.properties"),
+			Mappings: []config.LangMapping{
+				{Code: "en", File: ""},
+				{Code: "zh", File: "_zh", IsSource: true},
+				{Code: "zh_TW", File: "_zh_TW"},
+			},
+		},
+	}
+	ai.ResetTranslator()
+	return dir
+}
+
+// run runs a command of a minimal app with the flags the handlers read and
+// fails the test if it returns an error.
+func run(t *testing.T, action cli.ActionFunc, args ...string) {
+	t.Helper()
+	if err := runCommand(action, args...); err != nil {
+		t.Fatalf("%v: %v", args, err)
+	}
+}
+
+// runCommand is like run but returns the error of the command.
+func runCommand(action cli.ActionFunc, args ...string) error {
+	app := &cli.App{
+		Name: "i18n-manager",
+		Commands: []*cli.Command{{
+			Name: "cmd",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "key"},
+				&cli.BoolFlag{Name: "yes"},
+				&cli.StringFlag{Name: "key-style"},
+				&cli.StringFlag{Name: "key-prefix"},
+				&cli.StringFlag{Name: "on-conflict"},
+				&cli.BoolFlag{Name: "allow-duplicate"},
+				&cli.StringSliceFlag{Name: "lang"},
+				&cli.BoolFlag{Name: "dry-run"},
+				&cli.BoolFlag{Name: "fix"},
+				&cli.StringFlag{Name: "format"},
+				&cli.StringSliceFlag{Name: "fail-on"},
+			},
+			Action: action,
+		}},
+	}
+	return app.Run(append([]string{"i18n-manager", "cmd"}, args...))
+}
+
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestTranslate(t *testing.T) {
+	dir := setupProject(t, map[string]string{
+		"messages.properties":    "# English\nbutton.save=Save\n",
+		"messages_zh.properties": "button.save=\\u4fdd\\u5b58\n",
+	})
+
+	run(t, HandleTranslate, "--yes", "--key", "user.delete", "删除{0}")
+
+	want := map[string]string{
+		"messages.properties":       "# English\nbutton.save=Save\nuser.delete=Delete {0}\n",
+		"messages_zh.properties":    "button.save=\\u4fdd\\u5b58\nuser.delete=\\u5220\\u9664{0}\n",
+		"messages_zh_TW.properties": "user.delete=\\u522a\\u9664{0}\n",
+	}
+	for name, content := range want {
+		if got := readFile(t, dir, name); got != content {
+			t.Errorf("%s\n got: %q\nwant: %q", name, got, content)
+		}
+	}
+}
+
+func TestSync(t *testing.T) {
+	dir := setupProject(t, map[string]string{
+		"messages.properties":       "button.save=Save\n",
+		"messages_zh.properties":    "button.save=\\u4fdd\\u5b58\nuser.missing=\\u7528\\u6237{0}\\u4e0d\\u5b58\\u5728\n",
+		"messages_zh_TW.properties": "",
+	})
+
+	run(t, HandleSync)
+
+	want := map[string]string{
+		"messages.properties":       "button.save=Save\nuser.missing=User {0} not found\n",
+		"messages_zh_TW.properties": "button.save=\\u5132\\u5b58\nuser.missing=[zh_TW] \\u7528\\u6237{0}\\u4e0d\\u5b58\\u5728\n",
+	}
+	for name, content := range want {
+		if got := readFile(t, dir, name); got != content {
+			t.Errorf("%s\n got: %q\nwant: %q", name, got, content)
+		}
+	}
+}
+
+func TestTranslateReusesDuplicate(t *testing.T) {
+	dir := setupProject(t, map[string]string{
+		"messages.properties":    "button.save=Save\n",
+		"messages_zh.properties": "button.save=\\u4fdd\\u5b58\n",
+	})
+
+	run(t, HandleTranslate, "--yes", "保存")
+
+	want := map[string]string{
+		"messages.properties":       "button.save=Save\n",
+		"messages_zh.properties":    "button.save=\\u4fdd\\u5b58\n",
+		"messages_zh_TW.properties": "button.save=\\u5132\\u5b58\n",
+	}
+	for name, content := range want {
+		if got := readFile(t, dir, name); got != content {
+			t.Errorf("%s\n got: %q\nwant: %q", name, got, content)
+		}
+	}
+}
+
+// TestTranslateKeyConflict runs every case with --yes; the cases without
+// --on-conflict show its defaults.
+func TestTranslateKeyConflict(t *testing.T) {
+	// msg.save 与 msg.save.2 已被原文不同的文本占用
+	files := map[string]string{
+		"messages.properties":    "msg.save=Store\nmsg.save.2=Keep\nuser.delete=Delete {0}\n",
+		"messages_zh.properties": "msg.save=\\u5b58\\u50a8\nmsg.save.2=\\u4fdd\\u7559\nuser.delete=\\u5220\\u9664{0}\n",
+	}
+	tests := []struct {
+		name    string
+		args    []string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "reuse adds the missing languages",
+			args: []string{"--key", "user.delete", "--on-conflict", "reuse", "删除{0}"},
+			want: map[string]string{
+				"messages.properties":       files["messages.properties"],
+				"messages_zh.properties":    files["messages_zh.properties"],
+				"messages_zh_TW.properties": "user.delete=\\u522a\\u9664{0}\n",
+			},
+		},
+		{
+			name:    "reuse with a different source text",
+			args:    []string{"--key", "msg.save", "--on-conflict", "reuse", "保存"},
+			wantErr: "cannot reuse key 'msg.save'",
+		},
+		{
+			name: "suffix skips taken keys",
+			args: []string{"--key", "msg.save", "--on-conflict", "suffix", "保存"},
+			want: map[string]string{
+				"messages.properties":       files["messages.properties"] + "msg.save.3=Save\n",
+				"messages_zh.properties":    files["messages_zh.properties"] + "msg.save.3=\\u4fdd\\u5b58\n",
+				"messages_zh_TW.properties": "msg.save.3=\\u5132\\u5b58\n",
+			},
+		},
+		{
+			name: "overwrite",
+			args: []string{"--key", "msg.save", "--on-conflict", "overwrite", "保存"},
+			want: map[string]string{
+				"messages.properties":       "msg.save=Save\nmsg.save.2=Keep\nuser.delete=Delete {0}\n",
+				"messages_zh.properties":    "msg.save=\\u4fdd\\u5b58\nmsg.save.2=\\u4fdd\\u7559\nuser.delete=\\u5220\\u9664{0}\n",
+				"messages_zh_TW.properties": "msg.save=\\u5132\\u5b58\n",
+			},
+		},
+		{
+			name:    "abort",
+			args:    []string{"--key", "msg.save", "--on-conflict", "abort", "保存"},
+			wantErr: "key 'msg.save' already exists",
+		},
+		{
+			name:    "invalid action",
+			args:    []string{"--key", "msg.save", "--on-conflict", "merge", "保存"},
+			wantErr: "invalid --on-conflict 'merge'",
+		},
+		{
+			name: "--yes reuses the key of the same source text",
+			args: []string{"--key", "user.delete", "删除{0}"},
+			want: map[string]string{
+				"messages.properties":       files["messages.properties"],
+				"messages_zh.properties":    files["messages_zh.properties"],
+				"messages_zh_TW.properties": "user.delete=\\u522a\\u9664{0}\n",
+			},
+		},
+		{
+			name:    "--yes aborts for a key given with --key",
+			args:    []string{"--key", "msg.save", "保存"},
+			wantErr: "key 'msg.save' already exists",
+		},
+		{
+			name: "--yes adds a suffix to a generated key",
+			args: []string{"保存"},
+			want: map[string]string{
+				"messages.properties":       files["messages.properties"] + "msg.save.3=Save\n",
+				"messages_zh.properties":    files["messages_zh.properties"] + "msg.save.3=\\u4fdd\\u5b58\n",
+				"messages_zh_TW.properties": "msg.save.3=\\u5132\\u5b58\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupProject(t, files)
+
+			err := runCommand(HandleTranslate, append([]string{"--yes", "--allow-duplicate"}, tt.args...)...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				// 出错时不修改任何文件
+				tt.want = files
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			for name, content := range tt.want {
+				if got := readFile(t, dir, name); got != content {
+					t.Errorf("%s\n got: %q\nwant: %q", name, got, content)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(dir, "messages_zh_TW.properties")); tt.want["messages_zh_TW.properties"] == "" && err == nil {
+				t.Errorf("messages_zh_TW.properties was created")
+			}
+		})
+	}
+}
+
+func TestUniqueKey(t *testing.T) {
+	taken := map[string]bool{"a": true, "a.2": true, "a.3": true, "b.2": true}
+	tests := map[string]string{"a": "a.4", "b": "b", "c": "c"}
+	for key, want := range tests {
+		if got := uniqueKey(key, taken); got != want {
+			t.Errorf("uniqueKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
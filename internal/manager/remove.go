@@ -0,0 +1,109 @@
+package manager
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+// selectKeys returns the keys matched by the --key, --glob and --regex flags.
+// Exact keys are returned even if they are not defined anywhere.
+func selectKeys(c *cli.Context, translations []Translation) ([]string, error) {
+	exact := c.StringSlice("key")
+	glob := c.String("glob")
+	pattern := c.String("regex")
+	if len(exact) == 0 && glob == "" && pattern == "" {
+		return nil, fmt.Errorf("please specify --key, --glob or --regex")
+	}
+
+	var re *regexp.Regexp
+	if pattern != "" {
+		var err error
+		if re, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid regex '%s': %v", pattern, err)
+		}
+	}
+	if glob != "" {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob '%s': %v", glob, err)
+		}
+	}
+
+	selected := make(map[string]bool)
+	for _, key := range exact {
+		selected[key] = true
+	}
+	for _, t := range translations {
+		if glob != "" {
+			if ok, _ := path.Match(glob, t.Key); ok {
+				selected[t.Key] = true
+			}
+		}
+		if re != nil && re.MatchString(t.Key) {
+			selected[t.Key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(selected))
+	for key := range selected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func HandleRemove(c *cli.Context) error {
+	translations, err := loadAllTranslations()
+	if err != nil {
+		return fmt.Errorf("error loading translations: %v", err)
+	}
+
+	keys, err := selectKeys(c, translations)
+	if err != nil {
+		return err
+	}
+
+	tx := newTransaction()
+	removed := make(map[string][]string)
+	for _, mapping := range config.GetConfig().Language.Mappings {
+		filename := config.GetPropertiesFilePath(mapping.Code)
+		doc, err := tx.document(filename)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if doc.Delete(key) {
+				removed[filename] = append(removed[filename], key)
+			}
+		}
+	}
+
+	changed := tx.changed()
+	if len(changed) == 0 {
+		fmt.Println("No matching keys found")
+		return nil
+	}
+
+	for _, filename := range changed {
+		fmt.Printf("%s: %d key(s)\n", filename, len(removed[filename]))
+		for _, key := range removed[filename] {
+			fmt.Printf("  - %s\n", key)
+		}
+	}
+
+	if c.Bool("dry-run") {
+		fmt.Println("\nDry run, no files were changed")
+		return nil
+	}
+
+	if _, err := tx.commit(); err != nil {
+		return fmt.Errorf("error saving translations: %v", err)
+	}
+
+	fmt.Printf("\nRemoved keys from %d file(s)\n", len(changed))
+	return nil
+}
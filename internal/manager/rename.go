@@ -0,0 +1,127 @@
+package manager
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+// sourceExtensions lists the files that may reference message keys.
+var sourceExtensions = map[string]bool{
+	".java": true,
+	".html": true,
+	".jsp":  true,
+	".ftl":  true,
+}
+
+// skippedDirs are never searched for source files.
+var skippedDirs = map[string]bool{
+	".git":          true,
+	".i18n-manager": true,
+	"node_modules":  true,
+	"target":        true,
+	"build":         true,
+}
+
+// walkSources calls fn for every source file below root.
+func walkSources(root string, fn func(path string) error) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && skippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !sourceExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		return fn(path)
+	})
+}
+
+// rewriteKeyReferences replaces string literal references to oldKey, such as
+// "old.key", 'old.key' and Thymeleaf #{old.key}, with newKey.
+func rewriteKeyReferences(content, oldKey, newKey string) string {
+	replacer := strings.NewReplacer(
+		`"`+oldKey+`"`, `"`+newKey+`"`,
+		`'`+oldKey+`'`, `'`+newKey+`'`,
+		`#{`+oldKey+`}`, `#{`+newKey+`}`,
+		`#{`+oldKey+`(`, `#{`+newKey+`(`,
+	)
+	return replacer.Replace(content)
+}
+
+func HandleRename(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return fmt.Errorf("usage: i18n-manager rename OLD_KEY NEW_KEY")
+	}
+	oldKey, newKey := c.Args().Get(0), c.Args().Get(1)
+	if oldKey == newKey {
+		return fmt.Errorf("old and new key are the same")
+	}
+
+	tx := newTransaction()
+	found := false
+	for _, mapping := range config.GetConfig().Language.Mappings {
+		doc, err := tx.document(config.GetPropertiesFilePath(mapping.Code))
+		if err != nil {
+			return err
+		}
+		if _, exists := doc.Get(newKey); exists {
+			return fmt.Errorf("key '%s' already exists in %s", newKey, config.GetPropertiesFilePath(mapping.Code))
+		}
+		if doc.Rename(oldKey, newKey) {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("key '%s' not found", oldKey)
+	}
+
+	if src := c.String("src"); src != "" {
+		err := walkSources(src, func(path string) error {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("error reading %s: %v", path, err)
+			}
+			if rewritten := rewriteKeyReferences(string(data), oldKey, newKey); rewritten != string(data) {
+				tx.stage(path, data, []byte(rewritten))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	changed := tx.changed()
+	for _, filename := range changed {
+		f := tx.files[filename]
+		fmt.Print(unifiedDiff(filepath.ToSlash(filename), f.original, f.bytes()))
+	}
+
+	if c.Bool("dry-run") {
+		fmt.Println("\nDry run, no files were changed")
+		return nil
+	}
+
+	if !c.Bool("yes") && !confirm(fmt.Sprintf("\nApply these changes to %d file(s)? (y/N): ", len(changed))) {
+		fmt.Println("Rename cancelled")
+		return nil
+	}
+
+	if _, err := tx.commit(); err != nil {
+		return fmt.Errorf("error saving changes: %v", err)
+	}
+
+	fmt.Printf("Renamed '%s' to '%s' in %d file(s)\n", oldKey, newKey, len(changed))
+	return nil
+}
@@ -0,0 +1,213 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+// keyReferencePattern finds message keys in source files. The first group of
+// the expression is the key. Patterns with extensions only apply to files
+// with one of them.
+type keyReferencePattern struct {
+	re         *regexp.Regexp
+	extensions []string
+}
+
+var keyReferencePatterns = []keyReferencePattern{
+	// MessageSource / MessageSourceAccessor: getMessage("key", ...)，FreeMarker 中的 springMacroRequestContext.getMessage('key')
+	{re: regexp.MustCompile(`getMessage\(\s*["']([^"'\s]+)["']\s*[,)]`)},
+	// @Message("key") / @Message(value = "key")
+	{re: regexp.MustCompile(`@Message\(\s*(?:(?:value|key|code)\s*=\s*)?"([^"\s]+)"`)},
+	// Bean Validation: message = "{key}"
+	{re: regexp.MustCompile(`message\s*=\s*"\{([^}"\s]+)\}"`)},
+	// Thymeleaf: #{key} / #{key(args)}，Java 中的 #{...} 是 SpEL 表达式，不是消息键
+	{re: regexp.MustCompile(`#\{([A-Za-z0-9_][^}(\s'"]*)`), extensions: []string{".html"}},
+	// JSP: <spring:message code="key"/> / <fmt:message key="key"/>
+	{re: regexp.MustCompile(`<(?:spring|fmt):message\s[^>]*?(?:code|key)\s*=\s*["']([^"'\s]+)["']`), extensions: []string{".jsp"}},
+	// FreeMarker: <@spring.message "key"/> / <@spring.message code="key"/>
+	{re: regexp.MustCompile(`<@spring\.message(?:Text)?\s+(?:code\s*=\s*)?["']([^"'\s]+)["']`), extensions: []string{".ftl"}},
+}
+
+// appliesTo reports whether the pattern should be used for the file at path.
+func (p keyReferencePattern) appliesTo(path string) bool {
+	if len(p.extensions) == 0 {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range p.extensions {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// dynamicKeyPattern finds keys built at runtime such as
+// getMessage("error." + code). Every key with that prefix counts as used.
+var dynamicKeyPattern = regexp.MustCompile(`getMessage\(\s*["']([^"'\s]+)["']\s*\+`)
+
+// builtinKeyPrefixes are message keys provided by libraries, such as the
+// default Bean Validation messages.
+var builtinKeyPrefixes = []string{"javax.", "jakarta.", "org.hibernate.", "org.springframework."}
+
+// keyReference is a place in a source file that uses a message key.
+type keyReference struct {
+	Key  string
+	File string
+	Line int
+}
+
+// findKeyReferences returns the keys referenced in content and the prefixes
+// of keys that are built dynamically.
+func findKeyReferences(path, content string) ([]keyReference, []string) {
+	var refs []keyReference
+	for _, p := range keyReferencePatterns {
+		if !p.appliesTo(path) {
+			continue
+		}
+		for _, m := range p.re.FindAllStringSubmatchIndex(content, -1) {
+			key := content[m[2]:m[3]]
+			if hasAnyPrefix(key, builtinKeyPrefixes) {
+				continue
+			}
+			refs = append(refs, keyReference{
+				Key:  key,
+				File: path,
+				Line: strings.Count(content[:m[2]], "\n") + 1,
+			})
+		}
+	}
+
+	var prefixes []string
+	for _, m := range dynamicKeyPattern.FindAllStringSubmatch(content, -1) {
+		prefixes = append(prefixes, m[1])
+	}
+	return refs, prefixes
+}
+
+func HandleScan(c *cli.Context) error {
+	sourceLang := config.GetSourceLang()
+	if sourceLang == nil {
+		return fmt.Errorf("no source language configured")
+	}
+	translations, err := loadAllTranslations()
+	if err != nil {
+		return fmt.Errorf("error loading translations: %v", err)
+	}
+
+	src := c.String("src")
+	var refs []keyReference
+	var prefixes []string
+	files := 0
+	err = walkSources(src, func(path string) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", path, err)
+		}
+		r, p := findKeyReferences(filepath.ToSlash(path), string(data))
+		refs = append(refs, r...)
+		prefixes = append(prefixes, p...)
+		files++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	used := make(map[string]bool)
+	for _, ref := range refs {
+		used[ref.Key] = true
+	}
+
+	// 未使用的键：在任意语言文件中定义，但源码中没有引用
+	var unused []Translation
+	for _, t := range translations {
+		if used[t.Key] || hasAnyPrefix(t.Key, prefixes) {
+			continue
+		}
+		unused = append(unused, t)
+	}
+
+	// 未定义的键：源码中引用了，但源语言文件中没有
+	defined := make(map[string]bool)
+	for _, t := range translations {
+		if _, ok := t.Values[sourceLang.Code]; ok {
+			defined[t.Key] = true
+		}
+	}
+	undefined := make(map[string][]keyReference)
+	for _, ref := range refs {
+		if !defined[ref.Key] {
+			undefined[ref.Key] = append(undefined[ref.Key], ref)
+		}
+	}
+
+	fmt.Printf("Scanned %d source file(s), found %d reference(s) to %d key(s)\n", files, len(refs), len(used))
+
+	if !c.Bool("undefined-only") && len(unused) > 0 {
+		fmt.Printf("\nUnused keys (%d):\n", len(unused))
+		for _, t := range unused {
+			lang := definingLang(t, sourceLang.Code)
+			fmt.Printf("  %s (%s:%d)\n", t.Key, config.GetPropertiesFilePath(lang), t.Lines[lang])
+		}
+	}
+
+	if !c.Bool("unused-only") && len(undefined) > 0 {
+		keys := make([]string, 0, len(undefined))
+		for key := range undefined {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		fmt.Printf("\nUndefined keys (%d), missing from %s:\n", len(keys), config.GetPropertiesFilePath(sourceLang.Code))
+		for _, key := range keys {
+			fmt.Printf("  %s\n", key)
+			for _, ref := range undefined[key] {
+				fmt.Printf("    %s:%d\n", ref.File, ref.Line)
+			}
+		}
+	}
+
+	if len(prefixes) > 0 {
+		fmt.Printf("\nKeys starting with these prefixes are built at runtime and treated as used: %s\n", strings.Join(dedupe(prefixes), ", "))
+	}
+
+	if !c.Bool("unused-only") && len(undefined) > 0 {
+		return fmt.Errorf("found %d undefined key(s)", len(undefined))
+	}
+	if len(unused) == 0 && len(undefined) == 0 {
+		fmt.Println("\nAll keys are used and defined!")
+	}
+	return nil
+}
+
+// definingLang returns the language whose file is shown as the location of a
+// key: the preferred language if the key is defined there, otherwise the
+// first configured language that defines it.
+func definingLang(t Translation, preferred string) string {
+	if _, ok := t.Lines[preferred]; ok {
+		return preferred
+	}
+	for _, mapping := range config.GetConfig().Language.Mappings {
+		if _, ok := t.Lines[mapping.Code]; ok {
+			return mapping.Code
+		}
+	}
+	return preferred
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package manager
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindKeyReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		content  string
+		keys     []string
+		lines    []int
+		prefixes []string
+	}{
+		{
+			name: "java",
+			path: "src/UserController.java",
+			content: "@Message(\"user.title\")\n" +
+				"@Message(value = \"user.subtitle\")\n" +
+				"@NotNull(message = \"{user.name.required}\")\n" +
+				"@Size(message = \"{javax.validation.constraints.Size.message}\")\n" +
+				"String a = messageSource.getMessage(\"user.saved\", null, locale);\n" +
+				"String b = accessor.getMessage(\n    \"user.deleted\")\n" +
+				"String c = messageSource.getMessage(\"error.\" + code, null, locale);\n" +
+				"@Value(\"#{systemProperties.home}\")\n",
+			keys:     []string{"user.saved", "user.deleted", "user.title", "user.subtitle", "user.name.required"},
+			lines:    []int{5, 7, 1, 2, 3},
+			prefixes: []string{"error."},
+		},
+		{
+			name: "html",
+			path: "templates/user.HTML",
+			content: "<h1 th:text=\"#{user.title}\"></h1>\n" +
+				"<p th:text=\"#{user.greeting(${user.name})}\"></p>\n" +
+				"<p th:text=\"${#messages.msg('x')}\"></p>\n",
+			keys:  []string{"user.title", "user.greeting"},
+			lines: []int{1, 2},
+		},
+		{
+			name: "jsp",
+			path: "WEB-INF/user.jsp",
+			content: "<spring:message code=\"user.title\"/>\n" +
+				"<fmt:message key='user.subtitle'/>\n" +
+				"<spring:message text=\"x\" code=\"user.hint\" />\n" +
+				"${#{not.thymeleaf}}\n",
+			keys:  []string{"user.title", "user.subtitle", "user.hint"},
+			lines: []int{1, 2, 3},
+		},
+		{
+			name: "ftl",
+			path: "templates/user.ftl",
+			content: "<@spring.message \"user.title\"/>\n" +
+				"<@spring.messageText code='user.subtitle'/>\n" +
+				"${springMacroRequestContext.getMessage('user.hint')}\n" +
+				"${springMacroRequestContext.getMessage('status.' + status)}\n",
+			keys:     []string{"user.hint", "user.title", "user.subtitle"},
+			lines:    []int{3, 1, 2},
+			prefixes: []string{"status."},
+		},
+		{
+			name:    "template patterns in other files",
+			path:    "src/Template.java",
+			content: "String s = \"<spring:message code='user.title'/> #{user.name}\";\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs, prefixes := findKeyReferences(tt.path, tt.content)
+			var keys []string
+			var lines []int
+			for _, ref := range refs {
+				keys = append(keys, ref.Key)
+				lines = append(lines, ref.Line)
+			}
+			if !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("keys = %q, want %q", keys, tt.keys)
+			}
+			if !reflect.DeepEqual(lines, tt.lines) {
+				t.Errorf("lines = %v, want %v", lines, tt.lines)
+			}
+			if !reflect.DeepEqual(prefixes, tt.prefixes) {
+				t.Errorf("prefixes = %q, want %q", prefixes, tt.prefixes)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/SimonGino/i18n-manager/internal/ai"
+	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+// syncItem is a missing translation together with the source text it is
+// translated from.
+type syncItem struct {
+	missingTranslation
+	Source string
+}
+
+// collectSyncItems returns the missing translations selected by the --lang
+// and --key-prefix flags, and the keys skipped because their source-language
+// value is missing too.
+func collectSyncItems(c *cli.Context, translations []Translation, sourceLang string) ([]syncItem, []string) {
+	langs := make(map[string]bool)
+	for _, lang := range c.StringSlice("lang") {
+		langs[lang] = true
+	}
+	prefix := c.String("key-prefix")
+
+	values := make(map[string]map[string]string, len(translations))
+	for _, t := range translations {
+		values[t.Key] = t.Values
+	}
+
+	var items []syncItem
+	var skipped []string
+	for _, m := range findMissing(translations) {
+		if m.Lang == sourceLang || !strings.HasPrefix(m.Key, prefix) {
+			continue
+		}
+		if len(langs) > 0 && !langs[m.Lang] {
+			continue
+		}
+
+		source, ok := values[m.Key][sourceLang]
+		if !ok {
+			skipped = append(skipped, m.Key)
+			continue
+		}
+		items = append(items, syncItem{missingTranslation: m, Source: source})
+	}
+	return items, skipped
+}
+
+func HandleSync(c *cli.Context) error {
+	sourceLang := config.GetSourceLang()
+	if sourceLang == nil {
+		return fmt.Errorf("no source language configured")
+	}
+
+	translations, err := loadAllTranslations()
+	if err != nil {
+		return fmt.Errorf("error loading translations: %v", err)
+	}
+
+	items, skipped := collectSyncItems(c, translations, sourceLang.Code)
+	skipped = dedupe(skipped)
+	for _, key := range skipped {
+		fmt.Printf("Skipping key '%s': no %s source text\n", key, sourceLang.Code)
+	}
+	if len(items) == 0 {
+		fmt.Println("Nothing to sync, all selected translations are complete!")
+		return nil
+	}
+
+	if c.Bool("dry-run") {
+		for _, item := range items {
+			fmt.Printf("[%s] %s: %s\n", item.Lang, item.Key, item.Source)
+		}
+		fmt.Printf("\nDry run, %d translation(s) would be added\n", len(items))
+		return nil
+	}
+
+	// 按目标语言分组，每种语言批量翻译
+	var langs []string
+	byLang := make(map[string][]syncItem)
+	for _, item := range items {
+		if _, ok := byLang[item.Lang]; !ok {
+			langs = append(langs, item.Lang)
+		}
+		byLang[item.Lang] = append(byLang[item.Lang], item)
+	}
+
+	// 各语言并发翻译，结果按固定顺序输出
+	results := make([]map[string]string, len(langs))
+	errs := make([]error, len(langs))
+	ai.ForEach(len(langs), func(i int) {
+		results[i], errs[i] = translateSyncItems(byLang[langs[i]], sourceLang.Code, langs[i])
+	})
+
+	tx := newTransaction()
+	added := make(map[string]int)
+	warnings := 0
+	var failed []string
+	for i, lang := range langs {
+		group := byLang[lang]
+		err := errs[i]
+
+		var batchErr *ai.BatchError
+		if err != nil && !errors.As(err, &batchErr) {
+			fmt.Printf("[%s] error: %v\n", lang, err)
+		}
+
+		doc, docErr := tx.document(config.GetPropertiesFilePath(lang))
+		if docErr != nil {
+			return docErr
+		}
+		for _, item := range group {
+			translated, ok := results[i][item.Key]
+			if !ok {
+				if batchErr != nil {
+					fmt.Printf("[%s] %s: error: %v\n", lang, item.Key, batchErr.Failed[item.Key])
+				}
+				failed = append(failed, fmt.Sprintf("%s (%s)", item.Key, lang))
+				continue
+			}
+			translated = prepareValue(lang, item.Key, translated)
+			doc.Set(item.Key, translated)
+			added[lang]++
+			fmt.Printf("[%s] %s: %s\n", lang, item.Key, translated)
+			req := ai.TranslationRequest{Text: item.Source, SourceLang: sourceLang.Code, TargetLang: lang}
+			warnings += printGlossaryWarnings(req, translated, fmt.Sprintf("[%s] %s", lang, item.Key))
+		}
+	}
+
+	if _, err := tx.commit(); err != nil {
+		return fmt.Errorf("error saving translations: %v", err)
+	}
+
+	fmt.Println("\nSync summary:")
+	for _, mapping := range config.GetTargetLangs() {
+		if n := added[mapping.Code]; n > 0 {
+			fmt.Printf("  %s: %d added\n", mapping.Code, n)
+		}
+	}
+	if len(skipped) > 0 {
+		fmt.Printf("  skipped: %d (no source text)\n", len(skipped))
+	}
+	if warnings > 0 {
+		fmt.Printf("  glossary warnings: %d\n", warnings)
+	}
+	if len(failed) > 0 {
+		fmt.Printf("  failed: %d\n", len(failed))
+		for _, f := range failed {
+			fmt.Printf("    - %s\n", f)
+		}
+		return fmt.Errorf("%d translation(s) failed", len(failed))
+	}
+	return nil
+}
+
+// translateSyncItems translates the source text of items into lang.
+func translateSyncItems(items []syncItem, sourceLang, lang string) (map[string]string, error) {
+	if sourceLang == "zh" && lang == "zh_CN" {
+		// zh_CN 与 zh 内容相同，无需翻译
+		results := make(map[string]string, len(items))
+		for _, item := range items {
+			results[item.Key] = item.Source
+		}
+		return results, nil
+	}
+
+	batch := make([]ai.BatchItem, 0, len(items))
+	for _, item := range items {
+		batch = append(batch, ai.BatchItem{Key: item.Key, Text: item.Source})
+	}
+	return ai.TranslateBatch(ai.BatchRequest{
+		Items:      batch,
+		SourceLang: sourceLang,
+		TargetLang: lang,
+	})
+}
+
+// dedupe returns the distinct values of s in their original order.
+func dedupe(s []string) []string {
+	seen := make(map[string]bool, len(s))
+	var result []string
+	for _, v := range s {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
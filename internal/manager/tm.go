@@ -0,0 +1,294 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+	"github.com/SimonGino/i18n-manager/internal/tm"
+	"github.com/urfave/cli/v2"
+)
+
+// humanModelLabel is how entries without a model are shown and selected.
+const humanModelLabel = "human"
+
+func modelLabel(model string) string {
+	if model == tm.HumanModel {
+		return humanModelLabel
+	}
+	return model
+}
+
+// entryFilter builds a matcher from the --source-lang, --target-lang and
+// --model flags. Unset flags match everything.
+func entryFilter(c *cli.Context) func(tm.Entry) bool {
+	sourceLang := c.String("source-lang")
+	targetLang := c.String("target-lang")
+	model := c.String("model")
+	return func(e tm.Entry) bool {
+		return (sourceLang == "" || e.SourceLang == sourceLang) &&
+			(targetLang == "" || e.TargetLang == targetLang) &&
+			(model == "" || modelLabel(e.Model) == model)
+	}
+}
+
+func openMemory() (*tm.Memory, error) {
+	m, err := tm.Default()
+	if err != nil {
+		return nil, fmt.Errorf("error loading translation memory: %v", err)
+	}
+	return m, nil
+}
+
+func printEntry(e tm.Entry) {
+	fmt.Printf("[%s -> %s] %s\n", e.SourceLang, e.TargetLang, e.Source)
+	fmt.Printf("  %s  (%s, %d hit(s))\n", e.Target, modelLabel(e.Model), e.Hits)
+}
+
+func HandleTMList(c *cli.Context) error {
+	m, err := openMemory()
+	if err != nil {
+		return err
+	}
+
+	match := entryFilter(c)
+	var entries []tm.Entry
+	for _, e := range m.Entries() {
+		if match(e) {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) == 0 {
+		fmt.Println("Translation memory is empty")
+		return nil
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Source < entries[j].Source
+	})
+	for _, e := range entries {
+		printEntry(e)
+	}
+	fmt.Printf("\n%d entr(ies)\n", len(entries))
+	return nil
+}
+
+func HandleTMStats(c *cli.Context) error {
+	m, err := openMemory()
+	if err != nil {
+		return err
+	}
+
+	entries := m.Entries()
+	byModel := make(map[string]int)
+	for _, e := range entries {
+		byModel[modelLabel(e.Model)]++
+	}
+	models := make([]string, 0, len(byModel))
+	for model := range byModel {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+
+	stats := m.Stats()
+	fmt.Printf("Entries:    %d\n", len(entries))
+	for _, model := range models {
+		fmt.Printf("  %s: %d\n", model, byModel[model])
+	}
+	fmt.Printf("Hits:       %d\n", stats.Hits)
+	fmt.Printf("Misses:     %d (%d with similar references)\n", stats.Misses, stats.FuzzyHits)
+	if total := stats.Hits + stats.Misses; total > 0 {
+		fmt.Printf("Hit rate:   %.1f%%\n", float64(stats.Hits)*100/float64(total))
+	}
+	return nil
+}
+
+func HandleTMSearch(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("usage: i18n-manager tm search [--threshold 0.6] TEXT")
+	}
+	m, err := openMemory()
+	if err != nil {
+		return err
+	}
+
+	matches := m.Search(c.Args().First(), c.String("source-lang"), c.String("target-lang"), c.Float64("threshold"), c.Int("limit"))
+	if len(matches) == 0 {
+		fmt.Println("No similar entries found")
+		return nil
+	}
+	for _, match := range matches {
+		fmt.Printf("%3.0f%% ", match.Score*100)
+		printEntry(match.Entry)
+	}
+	return nil
+}
+
+// HandleTMImport loads entries from a JSON file created by 'tm export', or,
+// without --file, seeds the memory from the current properties files.
+func HandleTMImport(c *cli.Context) error {
+	filename := c.String("file")
+	if filename == "" {
+		return importBundles()
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", filename, err)
+	}
+	var entries []tm.Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("error parsing %s: %v", filename, err)
+	}
+
+	m, err := openMemory()
+	if err != nil {
+		return err
+	}
+	imported := 0
+	for _, e := range entries {
+		if e.Source == "" || e.Target == "" || e.SourceLang == "" || e.TargetLang == "" {
+			continue
+		}
+		m.Put(e)
+		imported++
+	}
+
+	fmt.Printf("Imported %d entr(ies) from %s\n", imported, filename)
+	return nil
+}
+
+// importBundles aligns the values of every key across the configured
+// languages and stores each source/target pair as a human-reviewed entry.
+// When the same source text is translated differently under several keys,
+// the most frequent translation wins.
+func importBundles() error {
+	sourceLang := config.GetSourceLang()
+	if sourceLang == nil {
+		return fmt.Errorf("no source language configured")
+	}
+	translations, err := loadAllTranslations()
+	if err != nil {
+		return fmt.Errorf("error loading translations: %v", err)
+	}
+
+	type pair struct{ source, targetLang string }
+	counts := make(map[pair]map[string]int)
+	var order []pair
+	for _, t := range translations {
+		source := t.Values[sourceLang.Code]
+		if strings.TrimSpace(source) == "" {
+			continue
+		}
+		for _, target := range config.GetTargetLangs() {
+			value := t.Values[target.Code]
+			// 与原文相同的值通常是尚未翻译的内容，不导入
+			if strings.TrimSpace(value) == "" || value == source {
+				continue
+			}
+			p := pair{source, target.Code}
+			if counts[p] == nil {
+				counts[p] = make(map[string]int)
+				order = append(order, p)
+			}
+			counts[p][value]++
+		}
+	}
+
+	m, err := openMemory()
+	if err != nil {
+		return err
+	}
+	perLang := make(map[string]int)
+	conflicts := 0
+	for _, p := range order {
+		best, bestCount := "", 0
+		for value, n := range counts[p] {
+			if n > bestCount || (n == bestCount && value < best) {
+				best, bestCount = value, n
+			}
+		}
+		if len(counts[p]) > 1 {
+			conflicts++
+		}
+		m.Put(tm.Entry{
+			Source:     p.source,
+			SourceLang: sourceLang.Code,
+			TargetLang: p.targetLang,
+			Model:      tm.HumanModel,
+			Target:     best,
+		})
+		perLang[p.targetLang]++
+	}
+
+	if len(order) == 0 {
+		fmt.Println("No translated pairs found in the properties files")
+		return nil
+	}
+	for _, target := range config.GetTargetLangs() {
+		if n := perLang[target.Code]; n > 0 {
+			fmt.Printf("%s -> %s: %d entr(ies)\n", sourceLang.Code, target.Code, n)
+		}
+	}
+	if conflicts > 0 {
+		fmt.Printf("%d source text(s) had different translations under different keys; the most frequent one was kept\n", conflicts)
+	}
+	fmt.Printf("Imported %d entr(ies) from %d key(s)\n", len(order), len(translations))
+	return nil
+}
+
+func HandleTMExport(c *cli.Context) error {
+	m, err := openMemory()
+	if err != nil {
+		return err
+	}
+
+	match := entryFilter(c)
+	entries := []tm.Entry{}
+	for _, e := range m.Entries() {
+		if match(e) {
+			entries = append(entries, e)
+		}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling translation memory: %v", err)
+	}
+
+	filename := c.String("file")
+	if filename == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %v", filename, err)
+	}
+	fmt.Printf("Exported %d entr(ies) to %s\n", len(entries), filename)
+	return nil
+}
+
+func HandleTMPurge(c *cli.Context) error {
+	olderThan := c.Duration("older-than")
+	if !c.Bool("all") && c.String("source-lang") == "" && c.String("target-lang") == "" &&
+		c.String("model") == "" && olderThan == 0 {
+		return fmt.Errorf("please specify --all or at least one of --source-lang, --target-lang, --model, --older-than")
+	}
+
+	m, err := openMemory()
+	if err != nil {
+		return err
+	}
+
+	match := entryFilter(c)
+	cutoff := time.Now().Add(-olderThan)
+	removed := m.Purge(func(e tm.Entry) bool {
+		return match(e) && (olderThan == 0 || e.Updated.Before(cutoff))
+	})
+
+	fmt.Printf("Removed %d entr(ies) from the translation memory\n", removed)
+	return nil
+}
@@ -0,0 +1,132 @@
+package manager
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/SimonGino/i18n-manager/internal/properties"
+)
+
+// transaction stages changes to several properties files and commits them as
+// a unit: either every changed file is updated or none of them is.
+type transaction struct {
+	files map[string]*stagedFile
+	order []string
+}
+
+type stagedFile struct {
+	doc      *properties.Document // nil for files staged as plain content
+	content  []byte
+	original []byte // nil if the file did not exist
+}
+
+func (f *stagedFile) bytes() []byte {
+	if f.doc != nil {
+		return f.doc.Bytes()
+	}
+	return f.content
+}
+
+func newTransaction() *transaction {
+	return &transaction{files: make(map[string]*stagedFile)}
+}
+
+// document returns the staged document for filename, loading it on first use.
+// Changes made to the document are written when the transaction commits.
+func (tx *transaction) document(filename string) (*properties.Document, error) {
+	if f, ok := tx.files[filename]; ok {
+		return f.doc, nil
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading %s: %v", filename, err)
+	}
+	if err == nil && data == nil {
+		data = []byte{}
+	}
+	doc, err := properties.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", filename, err)
+	}
+
+	tx.files[filename] = &stagedFile{doc: doc, original: data}
+	tx.order = append(tx.order, filename)
+	return doc, nil
+}
+
+// stage sets the new content of a file that is not a properties document,
+// such as a source file, so that it is committed with the bundles.
+func (tx *transaction) stage(filename string, original, content []byte) {
+	if f, ok := tx.files[filename]; ok {
+		f.content = content
+		return
+	}
+	tx.files[filename] = &stagedFile{content: content, original: original}
+	tx.order = append(tx.order, filename)
+}
+
+// changed returns the staged files whose content differs from disk.
+func (tx *transaction) changed() []string {
+	var changed []string
+	for _, filename := range tx.order {
+		f := tx.files[filename]
+		if !bytes.Equal(f.original, f.bytes()) {
+			changed = append(changed, filename)
+		}
+	}
+	return changed
+}
+
+// commit writes every changed file. All new contents are first written to
+// temp files next to their targets; only when that succeeded are they renamed
+// into place. If a rename fails, files already replaced are rolled back.
+func (tx *transaction) commit() ([]string, error) {
+	changed := tx.changed()
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	if _, err := createBackup(changed); err != nil {
+		return nil, fmt.Errorf("error creating backup: %v", err)
+	}
+
+	temps := make([]string, 0, len(changed))
+	removeTemps := func() {
+		for _, tmp := range temps {
+			os.Remove(tmp)
+		}
+	}
+	for _, filename := range changed {
+		tmp, err := createTempFile(filename, tx.files[filename].bytes())
+		if err != nil {
+			removeTemps()
+			return nil, err
+		}
+		temps = append(temps, tmp)
+	}
+
+	for i, filename := range changed {
+		if err := os.Rename(temps[i], filename); err != nil {
+			renameErr := fmt.Errorf("error replacing %s: %v", filename, err)
+			temps = temps[i:]
+			removeTemps()
+			if rbErr := tx.rollback(changed[:i]); rbErr != nil {
+				return nil, fmt.Errorf("%v; rollback failed: %v", renameErr, rbErr)
+			}
+			return nil, renameErr
+		}
+	}
+
+	return changed, nil
+}
+
+// rollback restores the original content of the given files.
+func (tx *transaction) rollback(filenames []string) error {
+	contents := make(map[string][]byte, len(filenames))
+	for _, filename := range filenames {
+		contents[filename] = tx.files[filename].original
+	}
+	return restoreFiles(contents)
+}
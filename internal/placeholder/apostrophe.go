@@ -0,0 +1,55 @@
+package placeholder
+
+import (
+	"regexp"
+	"strings"
+)
+
+// argumentPattern matches MessageFormat arguments such as {0} or {1,number}.
+var argumentPattern = regexp.MustCompile(`\{\d+(?:,[^{}]*)?\}`)
+
+// HasArguments reports whether s contains MessageFormat arguments, which
+// makes Spring format it with java.text.MessageFormat.
+func HasArguments(s string) bool {
+	return argumentPattern.MatchString(s)
+}
+
+// UnescapedApostrophes returns the byte offsets of the single quotes in s
+// that MessageFormat would swallow. Doubled quotes are escaped already, and a
+// quote next to a brace is taken as intentional quoting such as '{0}'.
+func UnescapedApostrophes(s string) []int {
+	var offsets []int
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\'' {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '\'' {
+			i++
+			continue
+		}
+		if (i > 0 && (s[i-1] == '{' || s[i-1] == '}')) || (i+1 < len(s) && (s[i+1] == '{' || s[i+1] == '}')) {
+			continue
+		}
+		offsets = append(offsets, i)
+	}
+	return offsets
+}
+
+// EscapeApostrophes doubles the quotes reported by UnescapedApostrophes, so
+// that MessageFormat shows the apostrophe of "Don't delete {0}".
+func EscapeApostrophes(s string) string {
+	offsets := UnescapedApostrophes(s)
+	if len(offsets) == 0 {
+		return s
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, i := range offsets {
+		b.WriteString(s[last : i+1])
+		b.WriteByte('\'')
+		last = i + 1
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package placeholder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHasArguments(t *testing.T) {
+	tests := map[string]bool{
+		"Save":            false,
+		"Delete {0}":      true,
+		"{1,number,#.##}": true,
+		"Hello {user}":    false,
+		"Hello ${user}":   false,
+		"Cost: %s":        false,
+		"{0} and '{1}'":   true,
+	}
+	for input, want := range tests {
+		if got := HasArguments(input); got != want {
+			t.Errorf("HasArguments(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestUnescapedApostrophes(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		offsets []int
+		escaped string
+	}{
+		{
+			name:    "none",
+			input:   "Delete {0}",
+			escaped: "Delete {0}",
+		},
+		{
+			name:    "apostrophe",
+			input:   "Don't delete {0}",
+			offsets: []int{3},
+			escaped: "Don''t delete {0}",
+		},
+		{
+			name:    "several",
+			input:   "It's {0}'s",
+			offsets: []int{2},
+			escaped: "It''s {0}'s",
+		},
+		{
+			name:    "already doubled",
+			input:   "Don''t delete {0}",
+			escaped: "Don''t delete {0}",
+		},
+		{
+			name:    "quoted braces",
+			input:   "Use '{0}' literally",
+			escaped: "Use '{0}' literally",
+		},
+		{
+			name:    "at the ends",
+			input:   "'quoted' {0}",
+			offsets: []int{0, 7},
+			escaped: "''quoted'' {0}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UnescapedApostrophes(tt.input); !reflect.DeepEqual(got, tt.offsets) {
+				t.Errorf("UnescapedApostrophes = %v, want %v", got, tt.offsets)
+			}
+			if got := EscapeApostrophes(tt.input); got != tt.escaped {
+				t.Errorf("EscapeApostrophes = %q, want %q", got, tt.escaped)
+			}
+			if got := EscapeApostrophes(tt.escaped); got != tt.escaped {
+				t.Errorf("EscapeApostrophes is not idempotent: %q", got)
+			}
+		})
+	}
+}
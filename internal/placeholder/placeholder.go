@@ -0,0 +1,176 @@
+// Package placeholder finds the parts of a message that must survive
+// translation unchanged: MessageFormat arguments such as {0} or {user},
+// printf verbs such as %s, Spring/EL expressions such as ${...} and HTML tags.
+package placeholder
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pattern matches a single placeholder. The alternatives are tried in order,
+// so ${name} is one placeholder and not "$" followed by {name}.
+var pattern = regexp.MustCompile(
+	`\$\{[^{}]*\}` + // ${expr}
+		`|\{[^{}]*\}` + // {0}, {1,number}, {name}
+		`|%(?:\d+\$)?[-+0#]*\d*(?:\.\d+)?[sdfgxXobceEt]` + // %s, %1$s, %.2f, see locate
+		`|</?[a-zA-Z][a-zA-Z0-9]*(?:\s[^<>]*)?/?>`) // <b>, </a>, <br/>
+
+// tokenPattern matches the tokens that replace placeholders while text is
+// translated.
+var tokenPattern = regexp.MustCompile(`⟦(\d+)⟧`)
+
+// locate returns the byte ranges of the placeholders of s. A printf verb
+// directly followed by an ASCII letter is prose such as "50%off" rather than a
+// placeholder; Go regexps have no lookahead, so those are dropped here.
+func locate(s string) [][]int {
+	matches := pattern.FindAllStringIndex(s, -1)
+	kept := matches[:0]
+	for _, m := range matches {
+		if s[m[0]] == '%' && m[1] < len(s) && isASCIILetter(s[m[1]]) {
+			continue
+		}
+		kept = append(kept, m)
+	}
+	return kept
+}
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// Find returns the placeholders of s in order of appearance.
+func Find(s string) []string {
+	var placeholders []string
+	for _, m := range locate(s) {
+		placeholders = append(placeholders, s[m[0]:m[1]])
+	}
+	return placeholders
+}
+
+// Token returns the mask used for the i-th placeholder.
+func Token(i int) string {
+	return fmt.Sprintf("⟦%d⟧", i)
+}
+
+// Mask replaces every placeholder of s with a numbered token and returns the
+// masked text together with the original placeholders.
+func Mask(s string) (string, []string) {
+	var b strings.Builder
+	var placeholders []string
+	last := 0
+	for _, m := range locate(s) {
+		b.WriteString(s[last:m[0]])
+		b.WriteString(Token(len(placeholders)))
+		placeholders = append(placeholders, s[m[0]:m[1]])
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String(), placeholders
+}
+
+// Unmask puts the placeholders back into a translated masked text. Every token
+// must appear exactly once; the translation may move tokens but not drop,
+// duplicate or invent them.
+func Unmask(s string, placeholders []string) (string, error) {
+	seen := make([]int, len(placeholders))
+	var unknown []string
+	result := tokenPattern.ReplaceAllStringFunc(s, func(token string) string {
+		i, _ := strconv.Atoi(tokenPattern.FindStringSubmatch(token)[1])
+		if i >= len(placeholders) {
+			unknown = append(unknown, token)
+			return token
+		}
+		seen[i]++
+		return placeholders[i]
+	})
+
+	if len(unknown) > 0 {
+		return "", fmt.Errorf("译文中包含未知的占位符标记: %s", strings.Join(unknown, " "))
+	}
+	var missing, repeated []string
+	for i, n := range seen {
+		switch {
+		case n == 0:
+			missing = append(missing, placeholders[i])
+		case n > 1:
+			repeated = append(repeated, placeholders[i])
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("译文中缺少占位符: %s", strings.Join(missing, " "))
+	}
+	if len(repeated) > 0 {
+		return "", fmt.Errorf("译文中的占位符重复: %s", strings.Join(repeated, " "))
+	}
+	return result, nil
+}
+
+// Diff compares the placeholders of a source text and its translation and
+// returns the ones missing from the translation and the ones it added, each
+// sorted.
+func Diff(source, translated string) (missing, extra []string) {
+	return difference(Find(source), Find(translated))
+}
+
+// Signature returns the placeholders of s that a translation must keep,
+// normalized and sorted: MessageFormat arguments, printf verbs and ${...}
+// expressions. HTML tags are left out, and whitespace inside arguments is
+// ignored, so "{1, number}" and "{1,number}" have the same signature.
+func Signature(s string) []string {
+	var signature []string
+	for _, p := range Find(s) {
+		if strings.HasPrefix(p, "<") {
+			continue
+		}
+		signature = append(signature, strings.Join(strings.Fields(p), ""))
+	}
+	sort.Strings(signature)
+	return signature
+}
+
+// CompareSignatures returns the signature placeholders of source missing
+// from translated and the ones translated added.
+func CompareSignatures(source, translated string) (missing, extra []string) {
+	return difference(Signature(source), Signature(translated))
+}
+
+// difference compares two multisets of placeholders.
+func difference(source, translated []string) (missing, extra []string) {
+	counts := make(map[string]int)
+	for _, p := range source {
+		counts[p]++
+	}
+	for _, p := range translated {
+		counts[p]--
+	}
+	for p, n := range counts {
+		for ; n > 0; n-- {
+			missing = append(missing, p)
+		}
+		for ; n < 0; n++ {
+			extra = append(extra, p)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(extra)
+	return missing, extra
+}
+
+// Validate returns an error describing how the placeholders of translated
+// differ from those of source, or nil if they are the same.
+func Validate(source, translated string) error {
+	missing, extra := Diff(source, translated)
+	switch {
+	case len(missing) > 0 && len(extra) > 0:
+		return fmt.Errorf("占位符与原文不一致: 缺少 %s，多出 %s", strings.Join(missing, " "), strings.Join(extra, " "))
+	case len(missing) > 0:
+		return fmt.Errorf("占位符与原文不一致: 缺少 %s", strings.Join(missing, " "))
+	case len(extra) > 0:
+		return fmt.Errorf("占位符与原文不一致: 多出 %s", strings.Join(extra, " "))
+	}
+	return nil
+}
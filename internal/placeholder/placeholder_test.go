@@ -0,0 +1,205 @@
+package placeholder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		masked       string
+		placeholders []string
+	}{
+		{
+			name:   "no placeholders",
+			input:  "Save",
+			masked: "Save",
+		},
+		{
+			name:         "MessageFormat arguments",
+			input:        "Delete {0} of {1,number} by {user}",
+			masked:       "Delete ⟦0⟧ of ⟦1⟧ by ⟦2⟧",
+			placeholders: []string{"{0}", "{1,number}", "{user}"},
+		},
+		{
+			name:         "printf verbs",
+			input:        "%s has %d items, %1$s costs %.2f",
+			masked:       "⟦0⟧ has ⟦1⟧ items, ⟦2⟧ costs ⟦3⟧",
+			placeholders: []string{"%s", "%d", "%1$s", "%.2f"},
+		},
+		{
+			name:         "printf verbs before non-ASCII letters",
+			input:        "%s个文件，共%d项",
+			masked:       "⟦0⟧个文件，共⟦1⟧项",
+			placeholders: []string{"%s", "%d"},
+		},
+		{
+			name:   "percent signs in prose",
+			input:  "50%off, 10%effective, 100%",
+			masked: "50%off, 10%effective, 100%",
+		},
+		{
+			name:         "expressions",
+			input:        "Hello ${user.name}",
+			masked:       "Hello ⟦0⟧",
+			placeholders: []string{"${user.name}"},
+		},
+		{
+			name:         "HTML tags",
+			input:        "<b>Bold</b><br/><a href=\"x\">link</a>",
+			masked:       "⟦0⟧Bold⟦1⟧⟦2⟧⟦3⟧link⟦4⟧",
+			placeholders: []string{"<b>", "</b>", "<br/>", "<a href=\"x\">", "</a>"},
+		},
+		{
+			name:         "not tags",
+			input:        "a < b and 1<2>0",
+			masked:       "a < b and 1<2>0",
+			placeholders: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			masked, placeholders := Mask(tt.input)
+			if masked != tt.masked {
+				t.Errorf("masked = %q, want %q", masked, tt.masked)
+			}
+			if !reflect.DeepEqual(placeholders, tt.placeholders) {
+				t.Errorf("placeholders = %q, want %q", placeholders, tt.placeholders)
+			}
+			if got := Find(tt.input); !reflect.DeepEqual(got, tt.placeholders) {
+				t.Errorf("Find = %q, want %q", got, tt.placeholders)
+			}
+		})
+	}
+}
+
+func TestUnmask(t *testing.T) {
+	placeholders := []string{"{0}", "%s"}
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "same order",
+			input: "删除⟦0⟧和⟦1⟧",
+			want:  "删除{0}和%s",
+		},
+		{
+			name:  "moved tokens",
+			input: "⟦1⟧ ⟦0⟧",
+			want:  "%s {0}",
+		},
+		{
+			name:    "missing token",
+			input:   "删除⟦0⟧",
+			wantErr: "缺少占位符: %s",
+		},
+		{
+			name:    "repeated token",
+			input:   "⟦0⟧⟦0⟧⟦1⟧",
+			wantErr: "占位符重复: {0}",
+		},
+		{
+			name:    "unknown token",
+			input:   "⟦0⟧⟦1⟧⟦2⟧",
+			wantErr: "未知的占位符标记: ⟦2⟧",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Unmask(tt.input, placeholders)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		source     string
+		translated string
+		wantErr    string
+	}{
+		{
+			name:       "same placeholders",
+			source:     "删除{0}<b>%s</b>",
+			translated: "<b>%s</b> delete {0}",
+		},
+		{
+			name:       "percent sign in prose",
+			source:     "打5折",
+			translated: "50%off",
+		},
+		{
+			name:       "missing",
+			source:     "删除{0}",
+			translated: "Delete",
+			wantErr:    "缺少 {0}",
+		},
+		{
+			name:       "extra",
+			source:     "删除",
+			translated: "Delete {0}",
+			wantErr:    "多出 {0}",
+		},
+		{
+			name:       "missing and extra",
+			source:     "删除{0}",
+			translated: "Delete {1}",
+			wantErr:    "缺少 {0}，多出 {1}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.source, tt.translated)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSignature(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{input: "Save", want: nil},
+		{input: "{1} and {0}", want: []string{"{0}", "{1}"}},
+		{input: "{1, number}", want: []string{"{1,number}"}},
+		{input: "<b>%s</b> ${a}", want: []string{"${a}", "%s"}},
+		{input: "50%off", want: nil},
+	}
+	for _, tt := range tests {
+		if got := Signature(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Signature(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	missing, extra := CompareSignatures("{0,number} <b>x</b>", "{0, number} <i>x</i>")
+	if missing != nil || extra != nil {
+		t.Errorf("CompareSignatures: missing %q, extra %q", missing, extra)
+	}
+}
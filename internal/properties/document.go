@@ -0,0 +1,276 @@
+package properties
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// Document is a properties file kept in its original form. Comments, blank
+// lines, separators and spacing are preserved, so editing one entry leaves
+// every other byte of the file untouched.
+type Document struct {
+	nodes   []*node
+	newline string
+}
+
+// node is one logical line of a document together with its raw text.
+type node struct {
+	kind lineKind
+	raw  string // original text, line terminator included
+
+	// Entry lines only. Offsets point into raw.
+	key, value string
+	keyStart   int
+	keyEnd     int
+	valueStart int
+	contentEnd int
+}
+
+// Parse reads a properties document.
+func Parse(data []byte) (*Document, error) {
+	src := string(data)
+	doc := &Document{newline: detectNewline(src)}
+
+	for _, l := range scanLines(src) {
+		n := &node{kind: l.kind, raw: src[l.start:l.end]}
+		if l.kind == entryLine {
+			keyEnd, valueStart := splitEntry(l.text)
+
+			var err error
+			if n.key, err = unescape(l.text[:keyEnd]); err != nil {
+				return nil, fmt.Errorf("line %d: %v", l.num, err)
+			}
+			if n.value, err = unescape(l.text[valueStart:]); err != nil {
+				return nil, fmt.Errorf("line %d: %v", l.num, err)
+			}
+
+			n.keyStart = l.pos[0] - l.start
+			n.keyEnd = rawOffset(l, keyEnd) - l.start
+			n.valueStart = rawOffset(l, valueStart) - l.start
+			n.contentEnd = l.contentEnd - l.start
+		}
+		doc.nodes = append(doc.nodes, n)
+	}
+	return doc, nil
+}
+
+// rawOffset maps an offset in the logical text of l to the source.
+func rawOffset(l line, i int) int {
+	if i < len(l.text) {
+		return l.pos[i]
+	}
+	if i > 0 {
+		return l.pos[i-1] + 1
+	}
+	return l.contentEnd
+}
+
+func detectNewline(src string) string {
+	if i := strings.IndexAny(src, "\r\n"); i >= 0 {
+		if strings.HasPrefix(src[i:], "\r\n") {
+			return "\r\n"
+		}
+		return src[i : i+1]
+	}
+	return "\n"
+}
+
+// countLines returns the number of natural lines in raw.
+func countLines(raw string) int {
+	n := strings.Count(raw, "\n") + strings.Count(raw, "\r") - strings.Count(raw, "\r\n")
+	if raw != "" && !strings.HasSuffix(raw, "\n") && !strings.HasSuffix(raw, "\r") {
+		n++
+	}
+	return n
+}
+
+// Entries returns the entries of the document in file order.
+func (d *Document) Entries() []Entry {
+	var entries []Entry
+	num := 1
+	for _, n := range d.nodes {
+		if n.kind == entryLine {
+			entries = append(entries, Entry{Key: n.key, Value: n.value, Line: num})
+		}
+		num += countLines(n.raw)
+	}
+	return entries
+}
+
+// lookup returns the effective entry for key, which is the last one in the
+// file, or nil.
+func (d *Document) lookup(key string) *node {
+	for i := len(d.nodes) - 1; i >= 0; i-- {
+		if n := d.nodes[i]; n.kind == entryLine && n.key == key {
+			return n
+		}
+	}
+	return nil
+}
+
+// Get returns the value of key as the JDK would load it.
+func (d *Document) Get(key string) (string, bool) {
+	if n := d.lookup(key); n != nil {
+		return n.value, true
+	}
+	return "", false
+}
+
+// Set updates the value of key in place, or appends a new entry at the end of
+// the document. Only the value part of an existing entry is rewritten.
+func (d *Document) Set(key, value string) {
+	if n := d.lookup(key); n != nil {
+		if n.value == value {
+			return
+		}
+		escaped := escape(value, false)
+		if n.valueStart == n.keyEnd {
+			// 没有分隔符的条目（只有键），需要补上分隔符，否则值会接在键后面
+			sep := d.separator()
+			n.raw = n.raw[:n.keyEnd] + sep + n.raw[n.keyEnd:]
+			n.valueStart += len(sep)
+			n.contentEnd += len(sep)
+		}
+		n.raw = n.raw[:n.valueStart] + escaped + n.raw[n.contentEnd:]
+		n.contentEnd = n.valueStart + len(escaped)
+		n.value = value
+		return
+	}
+
+	// 确保原有最后一行以换行结束
+	if len(d.nodes) > 0 {
+		last := d.nodes[len(d.nodes)-1]
+		if !strings.HasSuffix(last.raw, "\n") && !strings.HasSuffix(last.raw, "\r") {
+			last.raw += d.newline
+		}
+	}
+
+	escapedKey := escape(key, true)
+	escapedValue := escape(value, false)
+	sep := d.separator()
+	d.nodes = append(d.nodes, &node{
+		kind:       entryLine,
+		raw:        escapedKey + sep + escapedValue + d.newline,
+		key:        key,
+		value:      value,
+		keyEnd:     len(escapedKey),
+		valueStart: len(escapedKey) + len(sep),
+		contentEnd: len(escapedKey) + len(sep) + len(escapedValue),
+	})
+}
+
+// Delete removes every entry for key. The comment lines directly above an
+// entry are removed with it when they are set apart by a blank line; at the
+// top of the file they are usually a header or license, and right after
+// another entry they may belong to that one. It reports whether anything was
+// removed.
+func (d *Document) Delete(key string) bool {
+	removed := false
+	for i := len(d.nodes) - 1; i >= 0; i-- {
+		n := d.nodes[i]
+		if n.kind != entryLine || n.key != key {
+			continue
+		}
+
+		// 只有注释块前面是空行时才认为注释属于该条目，连同条目一起删除
+		start := i
+		for start > 0 && d.nodes[start-1].kind == commentLine {
+			start--
+		}
+		if start == 0 || d.nodes[start-1].kind != blankLine {
+			start = i
+		}
+		d.nodes = append(d.nodes[:start], d.nodes[i+1:]...)
+		i = start
+		removed = true
+	}
+	return removed
+}
+
+// Rename changes the key of every entry for oldKey to newKey, keeping the
+// separator and value untouched. It reports whether anything was renamed.
+func (d *Document) Rename(oldKey, newKey string) bool {
+	escaped := escape(newKey, true)
+	renamed := false
+	for _, n := range d.nodes {
+		if n.kind != entryLine || n.key != oldKey {
+			continue
+		}
+		delta := len(escaped) - (n.keyEnd - n.keyStart)
+		n.raw = n.raw[:n.keyStart] + escaped + n.raw[n.keyEnd:]
+		n.keyEnd += delta
+		n.valueStart += delta
+		n.contentEnd += delta
+		n.key = newKey
+		renamed = true
+	}
+	return renamed
+}
+
+// separator returns the separator used by the last entry of the document so
+// that new entries follow the style of the file.
+func (d *Document) separator() string {
+	for i := len(d.nodes) - 1; i >= 0; i-- {
+		n := d.nodes[i]
+		if n.kind != entryLine || n.valueStart == n.contentEnd {
+			continue
+		}
+		sep := n.raw[n.keyEnd:n.valueStart]
+		if sep != "" && !strings.ContainsAny(sep, "\\\r\n") {
+			return sep
+		}
+	}
+	return "="
+}
+
+// Bytes returns the serialized document.
+func (d *Document) Bytes() []byte {
+	var b strings.Builder
+	for _, n := range d.nodes {
+		b.WriteString(n.raw)
+	}
+	return []byte(b.String())
+}
+
+// escape converts a key or value into its properties file representation.
+// Characters outside printable ASCII are written as \uxxxx escapes.
+func escape(s string, isKey bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch r {
+		case ' ':
+			if isKey || i == 0 {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(' ')
+		case '\t':
+			b.WriteString("\\t")
+		case '\n':
+			b.WriteString("\\n")
+		case '\r':
+			b.WriteString("\\r")
+		case '\f':
+			b.WriteString("\\f")
+		case '\\':
+			b.WriteString("\\\\")
+		case '=', ':', '#', '!':
+			if isKey {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		default:
+			if r < 0x20 || r > 0x7e {
+				if r > 0xffff {
+					r1, r2 := utf16.EncodeRune(r)
+					fmt.Fprintf(&b, "\\u%04x\\u%04x", r1, r2)
+				} else {
+					fmt.Fprintf(&b, "\\u%04x", r)
+				}
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,238 @@
+// Package properties implements the Java .properties file format as read by
+// java.util.Properties.load.
+package properties
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Entry is a single key/value pair as the JDK would see it after loading.
+type Entry struct {
+	Key   string
+	Value string
+	Line  int // 1-based line number where the entry starts
+}
+
+type lineKind int
+
+const (
+	blankLine lineKind = iota
+	commentLine
+	entryLine
+)
+
+// line is one logical line of a properties file. Entry lines may span several
+// natural lines joined by backslash continuations.
+type line struct {
+	kind       lineKind
+	num        int    // 1-based number of the first natural line
+	start, end int    // source offsets of the raw text, terminator included
+	contentEnd int    // source offset of the last line terminator
+	text       string // logical content, continuations joined (entry lines only)
+	pos        []int  // source offset of every byte in text
+}
+
+// Load reads a properties document and returns its entries in file order.
+// Duplicate keys are returned as they appear; the last one wins in the JDK.
+func Load(r io.Reader) ([]Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Entries(), nil
+}
+
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\f'
+}
+
+// scanLines splits src into logical lines following the rules of
+// java.util.Properties.load: a natural line ending in an odd number of
+// backslashes continues on the next line, whose leading whitespace is skipped;
+// comment lines start with '#' or '!' and are never continued.
+func scanLines(src string) []line {
+	var lines []line
+	num := 1
+	i := 0
+	for i < len(src) {
+		l := line{num: num, start: i}
+
+		// 跳过行首空白
+		j := i
+		for j < len(src) && isWhitespace(src[j]) {
+			j++
+		}
+
+		switch {
+		case j == len(src) || src[j] == '\n' || src[j] == '\r':
+			l.kind = blankLine
+			l.contentEnd = j
+			i, num = skipNewline(src, j, num)
+		case src[j] == '#' || src[j] == '!':
+			l.kind = commentLine
+			for j < len(src) && src[j] != '\n' && src[j] != '\r' {
+				j++
+			}
+			l.contentEnd = j
+			i, num = skipNewline(src, j, num)
+		default:
+			l.kind = entryLine
+			i, num = scanEntry(src, j, num, &l)
+			if l.text == "" {
+				// 只有续行符、后面是空行或文件结尾的行，JDK 会忽略
+				l.kind = blankLine
+			}
+		}
+		l.end = i
+		lines = append(lines, l)
+	}
+	return lines
+}
+
+// scanEntry reads an entry starting at offset i and returns the offset and
+// line number following it.
+func scanEntry(src string, i, num int, l *line) (int, int) {
+	var text []byte
+	for {
+		backslashes := 0
+		for i < len(src) && src[i] != '\n' && src[i] != '\r' {
+			if src[i] == '\\' {
+				backslashes++
+			} else {
+				backslashes = 0
+			}
+			text = append(text, src[i])
+			l.pos = append(l.pos, i)
+			i++
+		}
+		l.contentEnd = i
+
+		continued := backslashes%2 == 1
+		if continued {
+			// 去掉续行符
+			text = text[:len(text)-1]
+			l.pos = l.pos[:len(l.pos)-1]
+		}
+
+		i, num = skipNewline(src, i, num)
+		if !continued || i == len(src) {
+			break
+		}
+
+		// 续行的行首空白会被忽略，空的续行结束该逻辑行
+		for i < len(src) && isWhitespace(src[i]) {
+			i++
+		}
+		if i == len(src) || src[i] == '\n' || src[i] == '\r' {
+			l.contentEnd = i
+			i, num = skipNewline(src, i, num)
+			break
+		}
+	}
+	l.text = string(text)
+	return i, num
+}
+
+// skipNewline consumes a single line terminator (\n, \r or \r\n) at offset i.
+func skipNewline(src string, i, num int) (int, int) {
+	if i >= len(src) {
+		return i, num
+	}
+	if src[i] == '\r' && i+1 < len(src) && src[i+1] == '\n' {
+		return i + 2, num + 1
+	}
+	return i + 1, num + 1
+}
+
+// splitEntry returns the offsets in text where the raw key ends and the raw
+// value starts.
+func splitEntry(text string) (keyEnd, valueStart int) {
+	keyEnd = len(text)
+	valueStart = len(text)
+	hasSep := false
+	escaped := false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if !escaped && (c == '=' || c == ':') {
+			keyEnd, valueStart, hasSep = i, i+1, true
+			break
+		}
+		if !escaped && isWhitespace(c) {
+			keyEnd, valueStart = i, i+1
+			break
+		}
+		escaped = c == '\\' && !escaped
+	}
+
+	for valueStart < len(text) {
+		c := text[valueStart]
+		if !isWhitespace(c) {
+			if !hasSep && (c == '=' || c == ':') {
+				hasSep = true
+			} else {
+				break
+			}
+		}
+		valueStart++
+	}
+	return keyEnd, valueStart
+}
+
+// unescape converts the escape sequences of a raw key or value.
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if i == len(s) {
+			break
+		}
+		switch c = s[i]; c {
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("malformed \\uxxxx encoding")
+			}
+			code, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\uxxxx encoding")
+			}
+			i += 4
+			r := rune(code)
+			// 处理 UTF-16 代理对
+			if r >= 0xD800 && r < 0xDC00 && i+6 < len(s) && s[i+1] == '\\' && s[i+2] == 'u' {
+				if low, err := strconv.ParseUint(s[i+3:i+7], 16, 16); err == nil && low >= 0xDC00 && low < 0xE000 {
+					r = (r-0xD800)<<10 + (rune(low) - 0xDC00) + 0x10000
+					i += 6
+				}
+			}
+			b.WriteRune(r)
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'n':
+			b.WriteByte('\n')
+		case 'f':
+			b.WriteByte('\f')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
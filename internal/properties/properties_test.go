@@ -0,0 +1,376 @@
+package properties
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Entry
+	}{
+		{
+			name:  "separators",
+			input: "a=1\nb:2\nc 3\nd\t4\ne = 5\nf : 6\ng   =   7\nh  i\nj = = k\n",
+			want: []Entry{
+				{Key: "a", Value: "1", Line: 1},
+				{Key: "b", Value: "2", Line: 2},
+				{Key: "c", Value: "3", Line: 3},
+				{Key: "d", Value: "4", Line: 4},
+				{Key: "e", Value: "5", Line: 5},
+				{Key: "f", Value: "6", Line: 6},
+				{Key: "g", Value: "7", Line: 7},
+				{Key: "h", Value: "i", Line: 8},
+				{Key: "j", Value: "= k", Line: 9},
+			},
+		},
+		{
+			name:  "key without value",
+			input: "a\nb=\nc :\n",
+			want: []Entry{
+				{Key: "a", Value: "", Line: 1},
+				{Key: "b", Value: "", Line: 2},
+				{Key: "c", Value: "", Line: 3},
+			},
+		},
+		{
+			name:  "comments",
+			input: "# hash\n! bang\n   # indented\n\t! indented\na=1 # not a comment\n",
+			want:  []Entry{{Key: "a", Value: "1 # not a comment", Line: 5}},
+		},
+		{
+			name:  "comment lines are never continued",
+			input: "# comment \\\na=1\n",
+			want:  []Entry{{Key: "a", Value: "1", Line: 2}},
+		},
+		{
+			name:  "continuation",
+			input: "a=one \\\n    two \\\n\tthree\nb=2\n",
+			want: []Entry{
+				{Key: "a", Value: "one two three", Line: 1},
+				{Key: "b", Value: "2", Line: 4},
+			},
+		},
+		{
+			name:  "continuation in key",
+			input: "long\\\n  key=v\n",
+			want:  []Entry{{Key: "longkey", Value: "v", Line: 1}},
+		},
+		{
+			name:  "even number of backslashes",
+			input: "a=x\\\\\nb=2\n",
+			want: []Entry{
+				{Key: "a", Value: `x\`, Line: 1},
+				{Key: "b", Value: "2", Line: 2},
+			},
+		},
+		{
+			name:  "continuation followed by blank line",
+			input: "a=1\\\n\nb=2\n",
+			want: []Entry{
+				{Key: "a", Value: "1", Line: 1},
+				{Key: "b", Value: "2", Line: 3},
+			},
+		},
+		{
+			name:  "lone backslash before blank line",
+			input: "a=1\n\\\n\nb=2\n",
+			want: []Entry{
+				{Key: "a", Value: "1", Line: 1},
+				{Key: "b", Value: "2", Line: 4},
+			},
+		},
+		{
+			name:  "lone backslash at end of file",
+			input: "a=1\n\\",
+			want:  []Entry{{Key: "a", Value: "1", Line: 1}},
+		},
+		{
+			name:  "lone backslash continued by entry",
+			input: "\\\n  b=2\n",
+			want:  []Entry{{Key: "b", Value: "2", Line: 1}},
+		},
+		{
+			name:  "escaped keys",
+			input: "a\\=b=1\nc\\:d:2\ne\\ f=3\n\\#g=4\n",
+			want: []Entry{
+				{Key: "a=b", Value: "1", Line: 1},
+				{Key: "c:d", Value: "2", Line: 2},
+				{Key: "e f", Value: "3", Line: 3},
+				{Key: "#g", Value: "4", Line: 4},
+			},
+		},
+		{
+			name:  "escapes in values",
+			input: "a=tab\\there\\nnew\\q\nb=\\ leading\n",
+			want: []Entry{
+				{Key: "a", Value: "tab\there\nnewq", Line: 1},
+				{Key: "b", Value: " leading", Line: 2},
+			},
+		},
+		{
+			name:  "unicode escapes",
+			input: "a=\\u4e2d\\u6587\nb=\\ud83d\\ude00\nc=直接\n",
+			want: []Entry{
+				{Key: "a", Value: "中文", Line: 1},
+				{Key: "b", Value: "😀", Line: 2},
+				{Key: "c", Value: "直接", Line: 3},
+			},
+		},
+		{
+			name:  "crlf and cr",
+			input: "a=1\r\nb=2\\\r\n  3\rc=4",
+			want: []Entry{
+				{Key: "a", Value: "1", Line: 1},
+				{Key: "b", Value: "23", Line: 2},
+				{Key: "c", Value: "4", Line: 4},
+			},
+		},
+		{
+			name:  "duplicates are kept in order",
+			input: "a=1\na=2\n",
+			want: []Entry{
+				{Key: "a", Value: "1", Line: 1},
+				{Key: "a", Value: "2", Line: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Load(%q) error: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load(%q)\n got: %+v\nwant: %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMalformedUnicode(t *testing.T) {
+	for _, input := range []string{"a=\\u12\n", "a=\\uzzzz\n"} {
+		if _, err := Load(strings.NewReader(input)); err == nil {
+			t.Errorf("Load(%q) succeeded, want error", input)
+		}
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	inputs := []string{
+		"",
+		"# header\n\na = 1\nb:2\r\nc  3\n",
+		"a=one \\\n    two\n! comment\n\n",
+		"a=1\n\\\n\nb=2\n",
+		"a=1\n\\",
+		"no-newline-at-end=x",
+	}
+	for _, input := range inputs {
+		doc, err := Parse([]byte(input))
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", input, err)
+		}
+		if got := string(doc.Bytes()); got != input {
+			t.Errorf("Parse(%q).Bytes() = %q", input, got)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		key, value string
+		want       string
+	}{
+		{
+			name:  "keeps separator and spacing",
+			input: "# header\na = 1\nb:2\n",
+			key:   "a",
+			value: "x",
+			want:  "# header\na = x\nb:2\n",
+		},
+		{
+			name:  "whitespace separator",
+			input: "a\t1\n",
+			key:   "a",
+			value: "x",
+			want:  "a\tx\n",
+		},
+		{
+			name:  "entry without separator",
+			input: "a=1\nkey\nb=2\n",
+			key:   "key",
+			value: "x",
+			want:  "a=1\nkey=x\nb=2\n",
+		},
+		{
+			name:  "entry without separator uses the style of the file",
+			input: "a : 1\nkey\n",
+			key:   "key",
+			value: "x",
+			want:  "a : 1\nkey : x\n",
+		},
+		{
+			name:  "entry with separator and empty value",
+			input: "key=\nb=2\n",
+			key:   "key",
+			value: "x",
+			want:  "key=x\nb=2\n",
+		},
+		{
+			name:  "continued value is replaced",
+			input: "a=one \\\n    two\nb=2\n",
+			key:   "a",
+			value: "x",
+			want:  "a=x\nb=2\n",
+		},
+		{
+			name:  "last duplicate is updated",
+			input: "a=1\na=2\n",
+			key:   "a",
+			value: "x",
+			want:  "a=1\na=x\n",
+		},
+		{
+			name:  "escapes value",
+			input: "a=1\r\n",
+			key:   "a",
+			value: " 中文\t😀",
+			want:  "a=\\ \\u4e2d\\u6587\\t\\ud83d\\ude00\r\n",
+		},
+		{
+			name:  "appends with newline of the file",
+			input: "a=1\r\n",
+			key:   "b c",
+			value: "2",
+			want:  "a=1\r\nb\\ c=2\r\n",
+		},
+		{
+			name:  "appends after last line without newline",
+			input: "a: 1",
+			key:   "b=c",
+			value: "2",
+			want:  "a: 1\nb\\=c: 2\n",
+		},
+		{
+			name:  "unchanged value keeps bytes",
+			input: "a = \\u0031\n",
+			key:   "a",
+			value: "1",
+			want:  "a = \\u0031\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.input, err)
+			}
+			doc.Set(tt.key, tt.value)
+			if got := string(doc.Bytes()); got != tt.want {
+				t.Errorf("Set(%q, %q) on %q\n got: %q\nwant: %q", tt.key, tt.value, tt.input, got, tt.want)
+			}
+
+			// 写出的文件重新加载后应得到设置的值
+			reloaded, err := Parse(doc.Bytes())
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", doc.Bytes(), err)
+			}
+			if got, ok := reloaded.Get(tt.key); !ok || got != tt.value {
+				t.Errorf("reloaded Get(%q) = %q, %v; want %q", tt.key, got, ok, tt.value)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		key   string
+		want  string
+	}{
+		{
+			name:  "comment set apart by a blank line",
+			input: "a=1\n\n# about b\nb=2\nc=3\n",
+			key:   "b",
+			want:  "a=1\n\nc=3\n",
+		},
+		{
+			name:  "header comment at the top of the file",
+			input: "# Copyright\n# License\na=1\nb=2\n",
+			key:   "a",
+			want:  "# Copyright\n# License\nb=2\n",
+		},
+		{
+			name:  "comment right after another entry",
+			input: "a=1\n# section\nb=2\n",
+			key:   "b",
+			want:  "a=1\n# section\n",
+		},
+		{
+			name:  "header followed by a blank line",
+			input: "# Copyright\n\n# about a\na=1\n",
+			key:   "a",
+			want:  "# Copyright\n\n",
+		},
+		{
+			name:  "continued value and duplicates",
+			input: "a=one \\\n    two\nb=2\na=3\n",
+			key:   "a",
+			want:  "b=2\n",
+		},
+		{
+			name:  "missing key",
+			input: "# header\na=1\n",
+			key:   "b",
+			want:  "# header\na=1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.input, err)
+			}
+			if removed := doc.Delete(tt.key); removed != (tt.input != tt.want) {
+				t.Errorf("Delete(%q) = %v", tt.key, removed)
+			}
+			if got := string(doc.Bytes()); got != tt.want {
+				t.Errorf("Delete(%q) on %q\n got: %q\nwant: %q", tt.key, tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRename(t *testing.T) {
+	input := "# a\nold = 1\nother=2\nold:3\n"
+	doc, err := Parse([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Rename("old", "new key") {
+		t.Fatal("Rename returned false")
+	}
+	want := "# a\nnew\\ key = 1\nother=2\nnew\\ key:3\n"
+	if got := string(doc.Bytes()); got != want {
+		t.Errorf("Rename\n got: %q\nwant: %q", got, want)
+	}
+	if doc.Rename("missing", "x") {
+		t.Error("Rename of a missing key returned true")
+	}
+
+	// 重命名后的条目仍可以修改
+	doc.Set("new key", "x")
+	want = "# a\nnew\\ key = 1\nother=2\nnew\\ key:x\n"
+	if got := string(doc.Bytes()); got != want {
+		t.Errorf("Set after Rename\n got: %q\nwant: %q", got, want)
+	}
+}
@@ -0,0 +1,337 @@
+// Package tm implements a persistent translation memory that maps source
+// text to earlier translations so they can be reused instead of paying for a
+// new AI request.
+package tm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SimonGino/i18n-manager/internal/config"
+)
+
+// HumanModel marks entries that were reviewed by people, such as entries
+// imported from existing bundles. They are preferred over AI results.
+const HumanModel = ""
+
+// Entry is one remembered translation.
+type Entry struct {
+	Source     string    `json:"source"`
+	SourceLang string    `json:"source_lang"`
+	TargetLang string    `json:"target_lang"`
+	Model      string    `json:"model"`
+	Target     string    `json:"target"`
+	Hits       int       `json:"hits"`
+	Updated    time.Time `json:"updated"`
+}
+
+// Stats counts lookups across all runs.
+type Stats struct {
+	Hits      int `json:"hits"`
+	FuzzyHits int `json:"fuzzy_hits"` // 找到相似条目作为参考的未命中次数
+	Misses    int `json:"misses"`
+}
+
+// Match is the result of a fuzzy lookup.
+type Match struct {
+	Entry
+	Score float64 // 相似度，1 表示完全相同
+}
+
+// Memory is a translation memory backed by a JSON file. It is safe for
+// concurrent use.
+type Memory struct {
+	mu      sync.Mutex
+	path    string
+	entries []Entry
+	stats   Stats
+	index   map[entryKey]int
+	dirty   bool
+}
+
+type entryKey struct {
+	source, sourceLang, targetLang, model string
+}
+
+type fileFormat struct {
+	Stats   Stats   `json:"stats"`
+	Entries []Entry `json:"entries"`
+}
+
+var (
+	defaultOnce   sync.Once
+	defaultMemory *Memory
+	defaultErr    error
+)
+
+// Default returns the translation memory stored in the config directory.
+func Default() (*Memory, error) {
+	defaultOnce.Do(func() {
+		defaultMemory, defaultErr = Open(filepath.Join(config.GetConfigDir(), "tm.json"))
+	})
+	return defaultMemory, defaultErr
+}
+
+// Flush saves the default memory if it was loaded and modified.
+func Flush() error {
+	if defaultMemory == nil {
+		return nil
+	}
+	return defaultMemory.Save()
+}
+
+// Open loads the memory stored at path. A missing file yields an empty memory.
+func Open(path string) (*Memory, error) {
+	m := &Memory{path: path, index: make(map[entryKey]int)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading translation memory: %v", err)
+	}
+
+	var f fileFormat
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("error parsing translation memory %s: %v", path, err)
+	}
+	m.stats = f.Stats
+	for _, e := range f.Entries {
+		m.put(e)
+	}
+	return m, nil
+}
+
+// Save writes the memory back to disk if it changed.
+func (m *Memory) Save() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.dirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(fileFormat{Stats: m.stats, Entries: m.entries}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling translation memory: %v", err)
+	}
+
+	// 先写临时文件再重命名，避免写入中断损坏记忆库
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("error writing translation memory: %v", err)
+	}
+	if err := os.Rename(tmp, m.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error writing translation memory: %v", err)
+	}
+	m.dirty = false
+	return nil
+}
+
+func (m *Memory) put(e Entry) {
+	k := entryKey{e.Source, e.SourceLang, e.TargetLang, e.Model}
+	if i, ok := m.index[k]; ok {
+		e.Hits = m.entries[i].Hits
+		m.entries[i] = e
+		return
+	}
+	m.index[k] = len(m.entries)
+	m.entries = append(m.entries, e)
+}
+
+// Put remembers a translation, replacing an earlier one for the same source
+// text, language pair and model.
+func (m *Memory) Put(e Entry) {
+	if e.Updated.IsZero() {
+		e.Updated = time.Now()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.put(e)
+	m.dirty = true
+}
+
+// Lookup returns the translation of source remembered for model, preferring
+// human-reviewed entries. Only exact matches are returned; hits and misses are
+// counted in the statistics.
+func (m *Memory) Lookup(source, sourceLang, targetLang, model string) (Entry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dirty = true
+
+	for _, candidate := range []string{HumanModel, model} {
+		if i, ok := m.index[entryKey{source, sourceLang, targetLang, candidate}]; ok {
+			m.entries[i].Hits++
+			m.stats.Hits++
+			return m.entries[i], true
+		}
+	}
+	m.stats.Misses++
+	return Entry{}, false
+}
+
+// Similar returns up to limit entries for model whose source text is at least
+// threshold similar to source but not identical, best first. They are meant
+// as references for a new translation, never as the translation itself. A
+// lookup that finds any is counted as a fuzzy hit.
+func (m *Memory) Similar(source, sourceLang, targetLang, model string, threshold float64, limit int) []Match {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var matches []Match
+	for _, match := range m.search(source, sourceLang, targetLang, threshold, 0, []string{model}) {
+		if match.Source != source {
+			matches = append(matches, match)
+		}
+		if len(matches) == limit {
+			break
+		}
+	}
+	if len(matches) > 0 {
+		m.stats.FuzzyHits++
+		m.dirty = true
+	}
+	return matches
+}
+
+// Search returns up to limit entries whose source text is at least threshold
+// similar to source, best first. Empty languages match any language; if
+// models are given, only human-reviewed entries and those models match.
+func (m *Memory) Search(source, sourceLang, targetLang string, threshold float64, limit int, models ...string) []Match {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.search(source, sourceLang, targetLang, threshold, limit, models)
+}
+
+func (m *Memory) search(source, sourceLang, targetLang string, threshold float64, limit int, models []string) []Match {
+	var matches []Match
+	for _, e := range m.entries {
+		if (sourceLang != "" && e.SourceLang != sourceLang) || (targetLang != "" && e.TargetLang != targetLang) {
+			continue
+		}
+		if len(models) > 0 && e.Model != HumanModel && !contains(models, e.Model) {
+			continue
+		}
+		if score := Similarity(source, e.Source); score >= threshold {
+			matches = append(matches, Match{Entry: e, Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		// 相似度相同时优先人工审核的条目
+		return matches[i].Model == HumanModel && matches[j].Model != HumanModel
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// Entries returns a copy of all entries.
+func (m *Memory) Entries() []Entry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Entry(nil), m.entries...)
+}
+
+// Stats returns the lookup statistics.
+func (m *Memory) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+// Purge removes every entry for which match returns true and reports how
+// many were removed. Statistics are reset when the memory becomes empty.
+func (m *Memory) Purge(match func(Entry) bool) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := m.entries[:0]
+	m.index = make(map[entryKey]int)
+	for _, e := range m.entries {
+		if match(e) {
+			continue
+		}
+		m.index[entryKey{e.Source, e.SourceLang, e.TargetLang, e.Model}] = len(kept)
+		kept = append(kept, e)
+	}
+
+	removed := len(m.entries) - len(kept)
+	m.entries = kept
+	if len(kept) == 0 {
+		m.stats = Stats{}
+	}
+	if removed > 0 {
+		m.dirty = true
+	}
+	return removed
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Similarity returns a score between 0 and 1 based on the edit distance of
+// the normalized strings, where 1 means identical.
+func Similarity(a, b string) float64 {
+	ra, rb := []rune(normalize(a)), []rune(normalize(b))
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// normalize lowercases s and collapses whitespace.
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}